@@ -1,7 +1,6 @@
 package main
 
 import (
-	"context"
 	"flag"
 	"log"
 	"net"
@@ -20,8 +19,17 @@ func main() {
 	port := flag.String("port", "9001", "Port to listen on")
 	dataDir := flag.String("data-dir", "./chunkserver_data", "Data directory for chunks")
 	masterAddr := flag.String("master", "localhost:9000", "Master server address")
+	advertiseAddr := flag.String("advertise-addr", "", "Address clients and the master use to reach this chunkserver (default localhost:<port>)")
+	zone := flag.String("zone", "", "Zone of this chunkserver, for spreading replicas")
+	rack := flag.String("rack", "", "Rack of this chunkserver, for spreading replicas")
+	host := flag.String("host", "", "Host of this chunkserver, for spreading replicas (default the hostname)")
+	scrubRate := flag.Int64("scrub-rate", chunkserver.DefaultScrubRate, "Bytes per second the background checksum scrubber reads (0 disables it)")
 	flag.Parse()
 
+	if *advertiseAddr == "" {
+		*advertiseAddr = "localhost:" + *port
+	}
+
 	// Created data directory
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -35,29 +43,48 @@ func main() {
 		log.Fatalf("Failed to listen: %v", err)
 	}
 
-	grpcServer := grpc.NewServer()
-
 	// Create chunkserver with data directory
 	chunkserverServer := chunkserver.NewServer(fullDataDir)
 
+	// Chunkservers sharing a machine share a failure domain
+	if *host == "" {
+		if *host, err = os.Hostname(); err != nil {
+			log.Printf("Failed to get hostname: %v", err)
+		}
+	}
+	chunkserverServer.Topology = &gfs.Topology{Zone: *zone, Rack: *rack, Host: *host}
+	chunkserverServer.ScrubRate = *scrubRate
+
+	// Count RPCs in progress so the master can see our load
+	grpcServer := grpc.NewServer(
+		grpc.UnaryInterceptor(chunkserverServer.UnaryInterceptor),
+		grpc.StreamInterceptor(chunkserverServer.StreamInterceptor),
+	)
+
 	// Register chunkserver service
 	gfs.RegisterChunkserverServer(grpcServer, chunkserverServer)
 
 	// Enable grpc reflection
 	reflection.Register(grpcServer)
 
-	log.Printf("Chunkserver listening on port %s, data director: %s", *port, fullDataDir)
+	log.Printf("Chunkserver %s listening on port %s (advertised as %s), data directory: %s, topology: zone=%q rack=%q host=%q",
+		chunkserverServer.ID, *port, *advertiseAddr, fullDataDir, *zone, *rack, *host)
 
 	// Register with master server
-	go registerWithMaster(*masterAddr, "localhost:"+*port)
+	go registerWithMaster(*masterAddr, *advertiseAddr, chunkserverServer)
+
+	// Look for silent corruption in chunks nobody reads
+	if *scrubRate > 0 {
+		go chunkserverServer.RunScrubber()
+	}
 
 	if err := grpcServer.Serve(lis); err != nil {
 		log.Fatalf("Failed to serve: %v", err)
 	}
 }
 
-// registerWithMaster registers this chunkserver with the master
-func registerWithMaster(masterAddr, chunkserverAddr string) {
+// registerWithMaster connects to the master and keeps this chunkserver checked in
+func registerWithMaster(masterAddr, chunkserverAddr string, chunkserverServer *chunkserver.Server) {
 	// Wait a bit for the server to start
 	time.Sleep(2 * time.Second)
 
@@ -69,23 +96,6 @@ func registerWithMaster(masterAddr, chunkserverAddr string) {
 	}
 	defer conn.Close()
 
-	masterClient := gfs.NewMasterClient(conn)
-
-	// Send periodic heartbeats
-	ticker := time.NewTicker(10 * time.Second)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		_, err := masterClient.Heartbeat(ctx, &gfs.HeartbeatRequest{
-			ChunkserverId: chunkserverAddr,
-		})
-		cancel()
-
-		if err != nil {
-			log.Printf("Failed to send heartbeat to master: %v", err)
-		} else {
-			log.Printf("Sent heartbeat to master")
-		}
-	}
+	// Heartbeats carry the master's commands, so the master never dials us for maintenance
+	chunkserverServer.RunHeartbeats(gfs.NewMasterClient(conn), chunkserverAddr)
 }
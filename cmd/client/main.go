@@ -6,12 +6,12 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/sdudhani/godfs/pkg/client"
 	"github.com/sdudhani/godfs/pkg/gfs"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 func main() {
@@ -20,14 +20,14 @@ func main() {
 	fmt.Println("A Distributed File System Client")
 	fmt.Println("")
 
-	// Connect to master server
-	conn, err := grpc.Dial("localhost:9000", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	// Connect to master server; file data moves directly to and from chunkservers
+	files, err := client.New("localhost:9000")
 	if err != nil {
 		log.Fatalf("❌ Failed to connect to master: %v", err)
 	}
-	defer conn.Close()
+	defer files.Close()
 
-	client := gfs.NewMasterClient(conn)
+	master := files.Master()
 	fmt.Println("✅ Connected to GoDFS master server!")
 	fmt.Println("")
 
@@ -46,13 +46,13 @@ func main() {
 
 		switch choice {
 		case "1":
-			uploadFile(client, scanner)
+			uploadFile(files, scanner)
 		case "2":
-			downloadFile(client, scanner)
+			downloadFile(files, scanner)
 		case "3":
-			listFiles(client)
+			listFiles(master)
 		case "4":
-			showSystemStatus(client)
+			showSystemStatus(master)
 		case "5":
 			showHelp()
 		case "6", "q", "quit", "exit":
@@ -77,7 +77,7 @@ func showMenu() {
 	fmt.Println("")
 }
 
-func uploadFile(client gfs.MasterClient, scanner *bufio.Scanner) {
+func uploadFile(files *client.Client, scanner *bufio.Scanner) {
 	fmt.Print("Enter filename: ")
 	if !scanner.Scan() {
 		return
@@ -95,30 +95,34 @@ func uploadFile(client gfs.MasterClient, scanner *bufio.Scanner) {
 	}
 	content := scanner.Text()
 
+	fmt.Print("Enter replication factor (empty for default): ")
+	if !scanner.Scan() {
+		return
+	}
+	var replicationFactor int
+	if value := strings.TrimSpace(scanner.Text()); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			fmt.Println("❌ Replication factor must be a positive number")
+			return
+		}
+		replicationFactor = n
+	}
+
 	fmt.Println("📤 Uploading file...")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	req := &gfs.UploadFileRequest{
-		Filename: filename,
-		Data:     []byte(content),
-	}
-
-	resp, err := client.UploadFile(ctx, req)
-	if err != nil {
+	if err := files.UploadWithReplication(ctx, filename, strings.NewReader(content), replicationFactor); err != nil {
 		fmt.Printf("❌ Upload failed: %v\n", err)
 		return
 	}
 
-	if resp.GetSuccess() {
-		fmt.Printf("✅ Upload successful: %s\n", resp.GetMessage())
-	} else {
-		fmt.Printf("❌ Upload failed: %s\n", resp.GetMessage())
-	}
+	fmt.Println("✅ Upload successful")
 }
 
-func downloadFile(client gfs.MasterClient, scanner *bufio.Scanner) {
+func downloadFile(files *client.Client, scanner *bufio.Scanner) {
 	fmt.Print("Enter filename to download: ")
 	if !scanner.Scan() {
 		return
@@ -135,22 +139,15 @@ func downloadFile(client gfs.MasterClient, scanner *bufio.Scanner) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	req := &gfs.DownloadFileRequest{
-		Filename: filename,
-	}
-
-	resp, err := client.DownloadFile(ctx, req)
-	if err != nil {
+	// Read the chunks straight from the chunkservers
+	var content strings.Builder
+	if err := files.Download(ctx, filename, &content); err != nil {
 		fmt.Printf("❌ Download failed: %v\n", err)
 		return
 	}
 
-	if resp.GetSuccess() {
-		fmt.Printf("✅ Download successful: %s\n", resp.GetMessage())
-		fmt.Printf("📄 File content: %s\n", string(resp.GetData()))
-	} else {
-		fmt.Printf("❌ Download failed: %s\n", resp.GetMessage())
-	}
+	fmt.Printf("✅ Download successful: %d bytes\n", content.Len())
+	fmt.Printf("📄 File content: %s\n", content.String())
 }
 
 func listFiles(client gfs.MasterClient) {
@@ -170,11 +167,15 @@ func listFiles(client gfs.MasterClient) {
 	}
 
 	if resp.GetSuccess() {
+		directories := resp.GetDirectories()
 		files := resp.GetFiles()
-		if len(files) == 0 {
+		if len(files) == 0 && len(directories) == 0 {
 			fmt.Println("📁 No files found")
 		} else {
-			fmt.Printf("📁 Found %d files:\n", len(files))
+			fmt.Printf("📁 Found %d directories and %d files:\n", len(directories), len(files))
+			for _, dir := range directories {
+				fmt.Printf("  📁 %s/\n", dir)
+			}
 			for i, file := range files {
 				fmt.Printf("  %d. %s\n", i+1, file)
 			}
@@ -201,22 +202,74 @@ func showSystemStatus(client gfs.MasterClient) {
 		return
 	}
 
-	if resp.GetSuccess() {
-		files := resp.GetFiles()
-		fmt.Println("✅ GoDFS System Status:")
-		fmt.Printf("  📊 Total files: %d\n", len(files))
-		fmt.Printf("  🖥️  Master server: Connected\n")
-		fmt.Printf("  📁 Files: %v\n", files)
-	} else {
+	if !resp.GetSuccess() {
 		fmt.Printf("❌ System check failed: %s\n", resp.GetMessage())
+		return
+	}
+
+	files := resp.GetFiles()
+	fmt.Println("✅ GoDFS System Status:")
+	fmt.Printf("  📊 Files in /: %d\n", len(files))
+	fmt.Printf("  🖥️  Master server: Connected\n")
+	fmt.Printf("  📁 Files: %v\n", files)
+
+	// Chunkserver membership, storage and load
+	csResp, err := client.ListChunkservers(ctx, &gfs.ListChunkserversRequest{})
+	if err != nil {
+		fmt.Printf("❌ List chunkservers failed: %v\n", err)
+		return
+	}
+	fmt.Printf("  💾 Chunkservers: %d\n", len(csResp.GetChunkservers()))
+	for _, cs := range csResp.GetChunkservers() {
+		health := "✅"
+		if !cs.GetHealthy() {
+			health = "❌"
+		}
+		stats := cs.GetStats()
+		flags := ""
+		switch {
+		case cs.GetDrained():
+			flags += ", drained"
+		case cs.GetDraining():
+			flags += fmt.Sprintf(", draining (%d chunks left)", cs.GetChunksToDrain())
+		case stats.GetDraining():
+			flags += ", draining"
+		}
+		if cs.GetFull() {
+			flags += ", full"
+		}
+		fmt.Printf("    %s %s (%s, %s): %d chunks, %d bytes used, %d of %d bytes free, %d active operations%s, last seen %s\n",
+			health, cs.GetAddress(), cs.GetId(), gfs.FormatTopology(cs.GetTopology()), stats.GetChunkCount(), stats.GetUsedBytes(), stats.GetFreeBytes(),
+			stats.GetTotalBytes(), stats.GetActiveOperations(), flags, time.Unix(cs.GetLastSeen(), 0).Format(time.TimeOnly))
+
+		if scrub := stats.GetScrub(); scrub != nil {
+			last := "never"
+			if scrub.GetLastScrub() > 0 {
+				last = time.Unix(scrub.GetLastScrub(), 0).Format(time.DateTime)
+			}
+			fmt.Printf("      🔎 Scrub: %d of %d chunks (%d of %d bytes) checked, last full pass %s, %d corrupt chunks found\n",
+				scrub.GetScrubbedChunks(), scrub.GetTotalChunks(), scrub.GetScrubbedBytes(), scrub.GetTotalBytes(), last, scrub.GetCorruptChunks())
+		}
+	}
+
+	replication := csResp.GetReplication()
+	fmt.Printf("  🔁 Re-replication: %d chunks queued, %d replicas missing, %d copies active\n",
+		replication.GetQueuedChunks(), replication.GetMissingReplicas(), replication.GetActiveCopies())
+
+	balancer := csResp.GetBalancer()
+	state := "stopped"
+	if balancer.GetRunning() {
+		state = "running"
 	}
+	fmt.Printf("  ⚖️  Balancer: %s, %.1f%% skew, %d moves active, %d chunks (%d bytes) moved\n",
+		state, 100*balancer.GetSkew(), balancer.GetActiveMoves(), balancer.GetMovedChunks(), balancer.GetMovedBytes())
 }
 
 func showHelp() {
 	fmt.Println("❓ GoDFS Client Help:")
 	fmt.Println("")
 	fmt.Println("📤 Upload File:")
-	fmt.Println("  - Enter a filename and content")
+	fmt.Println("  - Enter a filename, content and optionally a replication factor")
 	fmt.Println("  - File will be stored with replication")
 	fmt.Println("")
 	fmt.Println("📥 Download File:")
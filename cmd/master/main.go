@@ -1,8 +1,11 @@
 package main
 
 import (
+	"flag"
 	"log"
 	"net"
+	"os"
+	"path/filepath"
 
 	"github.com/sdudhani/godfs/internal/master"
 	"github.com/sdudhani/godfs/pkg/gfs"
@@ -11,23 +14,49 @@ import (
 )
 
 func main() {
+	// Command line flags for the master
+	metadataDir := flag.String("metadata-dir", "./master_data", "Directory for the operation log and checkpoints")
+	chunkSize := flag.Int64("chunk-size", master.DefaultChunkSize, "Maximum chunk size in bytes")
+	deletedFileRetention := flag.Duration("deleted-file-retention", master.DefaultDeletedFileRetention, "How long deleted files keep their chunks before garbage collection")
+	highWaterMark := flag.Float64("high-water-mark", master.DefaultHighWaterMark, "Disk utilization (0-1] at which chunkservers get no new chunks")
+	balance := flag.Bool("balance", false, "Run the balancer from startup")
+	balancerThreshold := flag.Float64("balancer-threshold", master.DefaultBalancerThreshold, "Utilization distance from the cluster average that makes the balancer move chunks")
+	balancerBandwidth := flag.Int64("balancer-bandwidth", master.DefaultBalancerBandwidth, "Bytes per second the balancer may move")
+	flag.Parse()
+
+	// Metadata lives next to the chunkserver data directories
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		log.Fatalf("Failed to get home directory: %v", err)
+	}
+
+	fullMetadataDir := filepath.Join(homeDir, ".godfs", *metadataDir)
+
 	// Master listens on port 9000
 	lis, err := net.Listen("tcp", ":9000")
 	if err != nil {
-		log.Fatal("Failed to listen %v", err)
+		log.Fatalf("Failed to listen %v", err)
 	}
 
 	grpcServer := grpc.NewServer()
 
-	masterServer := master.NewServer()
+	masterServer := master.NewServer(master.Config{
+		MetadataDir:          fullMetadataDir,
+		ChunkSize:            *chunkSize,
+		DeletedFileRetention: *deletedFileRetention,
+		HighWaterMark:        *highWaterMark,
+		StartBalancer:        *balance,
+		BalancerThreshold:    *balancerThreshold,
+		BalancerBandwidth:    *balancerBandwidth,
+	})
 
 	gfs.RegisterMasterServer(grpcServer, masterServer)
 
 	reflection.Register(grpcServer)
 
-	log.Println("Master server listening on Port 9000")
+	log.Printf("Master server listening on Port 9000, metadata directory: %s", fullMetadataDir)
 
 	if err := grpcServer.Serve(lis); err != nil {
-		log.Fatal("Failed to serve: %v", err)
+		log.Fatalf("Failed to serve: %v", err)
 	}
 }
@@ -2,14 +2,21 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"html/template"
 	"io"
 	"log"
+	"math"
+	"mime/multipart"
+	"net"
 	"net/http"
 	"os"
+	"path"
+	"strconv"
 	"time"
 
+	"github.com/sdudhani/godfs/pkg/client"
 	"github.com/sdudhani/godfs/pkg/gfs"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
@@ -17,6 +24,7 @@ import (
 
 type server struct {
 	masterAddr string
+	files      *client.Client // direct chunkserver data path
 }
 
 func (s *server) masterClient(ctx context.Context) (gfs.MasterClient, *grpc.ClientConn, error) {
@@ -76,6 +84,15 @@ var tmpl = template.Must(template.New("index").Parse(`<!doctype html>
         <h3>Master Server</h3>
         <p class="status-online">✅ Online</p>
         <p class="muted">{{.MasterAddr}}</p>
+        {{with .Replication}}<p class="muted">Re-replication: {{.QueuedChunks}} chunks queued • {{.MissingReplicas}} replicas missing • {{.ActiveCopies}} copies active</p>{{end}}
+        {{with .Balancer}}
+        <p class="muted">Balancer: {{if .Running}}running{{else}}stopped{{end}} • {{.Skew}}% skew • {{.ActiveMoves}} moves active • {{.MovedChunks}} chunks ({{.Moved}}) moved</p>
+        <form action="/balancer" method="post">
+          <input type="hidden" name="dir" value="{{$.Dir}}" />
+          <input type="hidden" name="running" value="{{if .Running}}false{{else}}true{{end}}" />
+          <button class="btn btn-secondary" type="submit">{{if .Running}}Stop{{else}}Start{{end}} Balancer</button>
+        </form>
+        {{end}}
       </div>
       {{range .Chunkservers}}
       <div class="status-card">
@@ -83,7 +100,24 @@ var tmpl = template.Must(template.New("index").Parse(`<!doctype html>
         <p class="{{if .Healthy}}status-online{{else}}status-offline{{end}}">
           {{if .Healthy}}✅ Online{{else}}❌ Offline{{end}}
         </p>
-        <p class="muted">{{.Address}}</p>
+        <p class="muted">{{.Address}} ({{.ID}})</p>
+        <p class="muted">Topology: {{.Topology}}</p>
+        <p class="muted">{{.ChunkCount}} chunks • {{.Used}} used • {{.Free}} free{{if .Total}} of {{.Total}} ({{.DiskUsage}}% full){{end}}</p>
+        <p class="muted">{{.Load}} active operations • seen {{.LastSeen}}</p>
+        {{if .Scrub}}<p class="muted">Scrub: {{.Scrub}}</p>{{end}}
+        {{if .Drained}}<p class="muted">🚧 Drained, safe to remove</p>{{else if .Draining}}<p class="muted">🚧 Draining, {{.ChunksToDrain}} chunks left to copy</p>{{end}}
+        {{if .Full}}<p class="muted">⚠️ Above the high-water mark, gets no new chunks</p>{{end}}
+        <form action="/drain" method="post">
+          <input type="hidden" name="dir" value="{{$.Dir}}" />
+          <input type="hidden" name="id" value="{{.ID}}" />
+          <input type="hidden" name="drain" value="{{if .Decommissioning}}false{{else}}true{{end}}" />
+          <button class="btn btn-secondary" type="submit">{{if .Decommissioning}}Cancel Drain{{else}}Drain{{end}}</button>
+        </form>
+      </div>
+      {{else}}
+      <div class="status-card">
+        <h3>Chunkservers</h3>
+        <p class="status-offline">❌ None registered</p>
       </div>
       {{end}}
     </div>
@@ -91,30 +125,49 @@ var tmpl = template.Must(template.New("index").Parse(`<!doctype html>
     <div class="card">
       <h2>📤 Upload File</h2>
       <form action="/upload" method="post" enctype="multipart/form-data">
+        <input type="hidden" name="dir" value="{{.Dir}}" />
+        <input type="number" name="replication" min="1" max="16" placeholder="Replicas (default 3)" />
         <input type="file" name="file" required />
         <div>
           <button class="btn" type="submit">Upload with Replication</button>
         </div>
       </form>
       <div class="replication-info">
-        <strong>🔄 Replication:</strong> Files are automatically replicated across {{.ReplicationFactor}} chunkservers for fault tolerance
+        <strong>🔄 Replication:</strong> Each file is replicated across as many chunkservers as its replication factor (3 unless you choose otherwise) for fault tolerance
       </div>
     </div>
 
     <div class="card">
-      <h2>📁 Files ({{len .Files}})</h2>
-      {{if .Files}}
+      <h2>📁 {{.Dir}} ({{len .Directories}} folders, {{len .Files}} files)</h2>
+      <form action="/mkdir" method="post">
+        <input type="hidden" name="dir" value="{{.Dir}}" />
+        <input type="text" name="name" placeholder="New folder name" required />
+        <button class="btn btn-secondary" type="submit">Create Folder</button>
+      </form>
+      {{if or .Parent .Directories .Files}}
         <ul class="files" style="list-style: none; padding: 0;">
+        {{if .Parent}}
+          <li><a class="file-name" href="/?dir={{.Parent}}">⬆️ ..</a></li>
+        {{end}}
+        {{range .Directories}}
+          <li><a class="file-name" href="/?dir={{.Path}}">📁 {{.Name}}/</a></li>
+        {{end}}
         {{range .Files}}
           <li>
             <div class="file-info">
               <div>
                 <div class="file-name">{{.Name}}</div>
-                <div class="muted">{{.Size}} bytes • {{.Replicas}} replicas</div>
+                <div class="muted">{{.Size}} bytes • {{.Replicas}} of {{.ReplicationFactor}} replicas{{if .Modified}} • modified {{.Modified}}{{end}}</div>
               </div>
               <div>
                 <span class="replication-badge">{{.Replicas}}x replicated</span>
-                <a class="btn" href="/download?filename={{.Name}}">Download</a>
+                <form action="/replication" method="post" style="display: inline;">
+                  <input type="hidden" name="dir" value="{{$.Dir}}" />
+                  <input type="hidden" name="path" value="{{.Path}}" />
+                  <input type="number" name="replication" min="1" max="16" value="{{.ReplicationFactor}}" style="width: 4em;" />
+                  <button class="btn btn-secondary" type="submit">Set</button>
+                </form>
+                <a class="btn" href="/download?filename={{.Path}}">Download</a>
               </div>
             </div>
           </li>
@@ -135,15 +188,83 @@ var tmpl = template.Must(template.New("index").Parse(`<!doctype html>
 </html>`))
 
 type FileInfo struct {
-	Name     string
-	Size     int64
-	Replicas int
+	Name              string
+	Path              string
+	Size              int64
+	Modified          string
+	Replicas          int
+	ReplicationFactor int32
+}
+
+type BalancerStatus struct {
+	Running     bool
+	Skew        int64
+	ActiveMoves int32
+	MovedChunks int64
+	Moved       string
+}
+
+type DirectoryInfo struct {
+	Name string
+	Path string
 }
 
 type ChunkserverStatus struct {
-	Address string
-	Port    string
-	Healthy bool
+	ID         string
+	Address    string
+	Port       string
+	Healthy    bool
+	ChunkCount int32
+	Used       string
+	Free       string
+	Total      string
+	DiskUsage  int64
+	Full       bool
+	Load       int32
+	LastSeen   string
+	Draining   bool
+	Topology   string
+	Scrub      string
+
+	// Decommissioning is set when the master drains the chunkserver for removal
+	Decommissioning bool
+	ChunksToDrain   int32
+	Drained         bool
+}
+
+// formatScrub summarizes the progress of a chunkserver's scrubber
+func formatScrub(scrub *gfs.ScrubStatus) string {
+	if scrub == nil {
+		return ""
+	}
+
+	var progress string
+	if scrub.GetRunning() {
+		progress = fmt.Sprintf("%d of %d chunks (%s of %s) checked", scrub.GetScrubbedChunks(), scrub.GetTotalChunks(),
+			formatBytes(scrub.GetScrubbedBytes()), formatBytes(scrub.GetTotalBytes()))
+	} else {
+		progress = "idle"
+	}
+
+	last := "never finished"
+	if scrub.GetLastScrub() > 0 {
+		last = "last finished " + time.Since(time.Unix(scrub.GetLastScrub(), 0)).Round(time.Second).String() + " ago"
+	}
+	return fmt.Sprintf("%s • %s • %d corrupt chunks found", progress, last, scrub.GetCorruptChunks())
+}
+
+// formatBytes renders a byte count with a binary unit
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
 }
 
 func (s *server) handleIndex(w http.ResponseWriter, r *http.Request) {
@@ -156,54 +277,130 @@ func (s *server) handleIndex(w http.ResponseWriter, r *http.Request) {
 	}
 	defer conn.Close()
 
-	// Get file list
-	resp, err := client.ListFiles(ctx, &gfs.ListFilesRequest{Path: ""})
+	dir := r.URL.Query().Get("dir")
+	if dir == "" {
+		dir = "/"
+	}
+
+	// Get the contents of the current directory
+	resp, err := client.ListFiles(ctx, &gfs.ListFilesRequest{Path: dir, IncludeAttributes: true})
 	if err != nil {
 		http.Error(w, fmt.Sprintf("list files failed: %v", err), http.StatusBadGateway)
 		return
 	}
+	if !resp.GetSuccess() {
+		http.Error(w, fmt.Sprintf("list files failed: %s", resp.GetMessage()), http.StatusNotFound)
+		return
+	}
 
-	// Get file details with replication info
-	var files []FileInfo
-	for _, filename := range resp.GetFiles() {
-		// Get chunk locations to determine replication
-		locationsResp, err := client.GetChunkLocations(ctx, &gfs.GetChunkLocationsRequest{
-			Filename:   filename,
-			ChunkIndex: 0,
-		})
+	var directories []DirectoryInfo
+	for _, subdir := range resp.GetDirectories() {
+		directories = append(directories, DirectoryInfo{Name: path.Base(subdir), Path: subdir})
+	}
 
+	// A file is only as replicated as its least replicated chunk
+	var files []FileInfo
+	for _, info := range resp.GetFileInfos() {
 		replicas := 0
-		if err == nil {
-			replicas = len(locationsResp.GetChunkserverAddresses())
+		for i, chunk := range info.GetChunks() {
+			if n := len(chunk.GetChunkserverAddresses()); i == 0 || n < replicas {
+				replicas = n
+			}
+		}
+
+		var modified string
+		if info.GetModifiedAt() != 0 {
+			modified = time.Unix(info.GetModifiedAt(), 0).Format("2006-01-02 15:04:05")
 		}
 
-		// Get file size (simplified - in real system, this would be in metadata)
 		files = append(files, FileInfo{
-			Name:     filename,
-			Size:     1024, // Placeholder - would get from metadata
-			Replicas: replicas,
+			Name:              path.Base(info.GetPath()),
+			Path:              info.GetPath(),
+			Size:              info.GetSize(),
+			Modified:          modified,
+			Replicas:          replicas,
+			ReplicationFactor: info.GetReplicationFactor(),
 		})
 	}
 
-	// Mock chunkserver status (in real system, this would come from master)
-	chunkservers := []ChunkserverStatus{
-		{Address: "localhost:9001", Port: "9001", Healthy: true},
-		{Address: "localhost:9002", Port: "9002", Healthy: true},
-		{Address: "localhost:9003", Port: "9003", Healthy: true},
+	// Cluster membership as the master sees it
+	csResp, err := client.ListChunkservers(ctx, &gfs.ListChunkserversRequest{})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("list chunkservers failed: %v", err), http.StatusBadGateway)
+		return
+	}
+	var chunkservers []ChunkserverStatus
+	for _, cs := range csResp.GetChunkservers() {
+		_, port, err := net.SplitHostPort(cs.GetAddress())
+		if err != nil {
+			port = cs.GetAddress()
+		}
+		stats := cs.GetStats()
+		var total string
+		var diskUsage int64
+		if stats.GetTotalBytes() > 0 {
+			total = formatBytes(stats.GetTotalBytes())
+			diskUsage = 100 * (stats.GetTotalBytes() - stats.GetFreeBytes()) / stats.GetTotalBytes()
+		}
+		chunkservers = append(chunkservers, ChunkserverStatus{
+			ID:         cs.GetId(),
+			Address:    cs.GetAddress(),
+			Port:       port,
+			Healthy:    cs.GetHealthy(),
+			ChunkCount: stats.GetChunkCount(),
+			Used:       formatBytes(stats.GetUsedBytes()),
+			Free:       formatBytes(stats.GetFreeBytes()),
+			Total:      total,
+			DiskUsage:  diskUsage,
+			Full:       cs.GetFull(),
+			Load:       stats.GetActiveOperations(),
+			LastSeen:   time.Since(time.Unix(cs.GetLastSeen(), 0)).Round(time.Second).String() + " ago",
+			Draining:   stats.GetDraining() || cs.GetDraining(),
+			Topology:   gfs.FormatTopology(cs.GetTopology()),
+			Scrub:      formatScrub(stats.GetScrub()),
+
+			Decommissioning: cs.GetDraining(),
+			ChunksToDrain:   cs.GetChunksToDrain(),
+			Drained:         cs.GetDrained(),
+		})
+	}
+
+	var balancer *BalancerStatus
+	if status := csResp.GetBalancer(); status != nil {
+		balancer = &BalancerStatus{
+			Running:     status.GetRunning(),
+			Skew:        int64(math.Round(100 * status.GetSkew())),
+			ActiveMoves: status.GetActiveMoves(),
+			MovedChunks: status.GetMovedChunks(),
+			Moved:       formatBytes(status.GetMovedBytes()),
+		}
+	}
+
+	var parent string
+	if dir != "/" {
+		parent = path.Dir(dir)
 	}
 
 	data := struct {
-		Files             []FileInfo
-		Chunkservers      []ChunkserverStatus
-		ReplicationFactor int
-		Flash             string
-		MasterAddr        string
+		Dir          string
+		Parent       string
+		Directories  []DirectoryInfo
+		Files        []FileInfo
+		Chunkservers []ChunkserverStatus
+		Flash        string
+		MasterAddr   string
+		Replication  *gfs.ReplicationStatus
+		Balancer     *BalancerStatus
 	}{
-		Files:             files,
-		Chunkservers:      chunkservers,
-		ReplicationFactor: 3,
-		Flash:             r.URL.Query().Get("flash"),
-		MasterAddr:        s.masterAddr,
+		Dir:          dir,
+		Parent:       parent,
+		Directories:  directories,
+		Files:        files,
+		Chunkservers: chunkservers,
+		Flash:        r.URL.Query().Get("flash"),
+		MasterAddr:   s.masterAddr,
+		Replication:  csResp.GetReplication(),
+		Balancer:     balancer,
 	}
 	if err := tmpl.Execute(w, data); err != nil {
 		log.Printf("template execute error: %v", err)
@@ -215,66 +412,194 @@ func (s *server) handleUpload(w http.ResponseWriter, r *http.Request) {
 		http.Redirect(w, r, "/", http.StatusSeeOther)
 		return
 	}
-	if err := r.ParseMultipartForm(32 << 20); err != nil {
+	// Stream the multipart body instead of buffering the whole file
+	reader, err := r.MultipartReader()
+	if err != nil {
 		http.Error(w, fmt.Sprintf("invalid form: %v", err), http.StatusBadRequest)
 		return
 	}
-	file, hdr, err := r.FormFile("file")
+	// The form sends the target directory and replication factor before the file
+	dir := "/"
+	var replicationFactor int
+	var part *multipart.Part
+	for {
+		part, err = reader.NextPart()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("file missing: %v", err), http.StatusBadRequest)
+			return
+		}
+		if part.FormName() == "file" && part.FileName() != "" {
+			break
+		}
+		if part.FormName() == "dir" {
+			value, err := io.ReadAll(io.LimitReader(part, 4096))
+			if err == nil && len(value) > 0 {
+				dir = string(value)
+			}
+		}
+		if part.FormName() == "replication" {
+			value, err := io.ReadAll(io.LimitReader(part, 16))
+			if err == nil && len(value) > 0 {
+				if replicationFactor, err = strconv.Atoi(string(value)); err != nil {
+					part.Close()
+					http.Error(w, fmt.Sprintf("invalid replication factor %q", value), http.StatusBadRequest)
+					return
+				}
+			}
+		}
+		part.Close()
+	}
+	defer part.Close()
+	filename := path.Join(dir, part.FileName())
+
+	// Chunk data goes straight to the chunkservers; the master only sees metadata
+	if err := s.files.UploadWithReplication(r.Context(), filename, part, replicationFactor); err != nil {
+		http.Error(w, fmt.Sprintf("upload failed: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	http.Redirect(w, r, "/?dir="+template.URLQueryEscaper(dir)+"&flash="+template.URLQueryEscaper("Uploaded "+filename), http.StatusSeeOther)
+}
+
+func (s *server) handleMkdir(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+	dir := r.FormValue("dir")
+	newDir := path.Join(dir, r.FormValue("name"))
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+	resp, err := s.files.Master().Mkdir(ctx, &gfs.MkdirRequest{Path: newDir})
 	if err != nil {
-		http.Error(w, fmt.Sprintf("file missing: %v", err), http.StatusBadRequest)
+		http.Error(w, fmt.Sprintf("mkdir failed: %v", err), http.StatusBadGateway)
 		return
 	}
-	defer file.Close()
 
-	// Read file fully (simple demo; for large files, chunking would be better)
-	data, err := io.ReadAll(file)
+	flash := "Created " + newDir
+	if !resp.GetSuccess() {
+		flash = "Create folder failed: " + resp.GetMessage()
+	}
+	http.Redirect(w, r, "/?dir="+template.URLQueryEscaper(dir)+"&flash="+template.URLQueryEscaper(flash), http.StatusSeeOther)
+}
+
+func (s *server) handleSetReplication(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+	dir := r.FormValue("dir")
+	filename := r.FormValue("path")
+	replicationFactor, err := strconv.Atoi(r.FormValue("replication"))
 	if err != nil {
-		http.Error(w, fmt.Sprintf("read failed: %v", err), http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("invalid replication factor %q", r.FormValue("replication")), http.StatusBadRequest)
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
-	client, conn, err := s.masterClient(ctx)
+	resp, err := s.files.Master().SetReplication(ctx, &gfs.SetReplicationRequest{
+		Path:              filename,
+		ReplicationFactor: int32(replicationFactor),
+	})
 	if err != nil {
-		http.Error(w, fmt.Sprintf("failed to connect to master: %v", err), http.StatusBadGateway)
+		http.Error(w, fmt.Sprintf("set replication failed: %v", err), http.StatusBadGateway)
 		return
 	}
-	defer conn.Close()
 
-	_, err = client.UploadFile(ctx, &gfs.UploadFileRequest{Filename: hdr.Filename, Data: data})
+	flash := resp.GetMessage()
+	if !resp.GetSuccess() {
+		flash = "Set replication failed: " + resp.GetMessage()
+	}
+	http.Redirect(w, r, "/?dir="+template.URLQueryEscaper(dir)+"&flash="+template.URLQueryEscaper(flash), http.StatusSeeOther)
+}
+
+func (s *server) handleBalancer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+	dir := r.FormValue("dir")
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+	resp, err := s.files.Master().SetBalancer(ctx, &gfs.SetBalancerRequest{
+		Running: r.FormValue("running") == "true",
+	})
 	if err != nil {
-		http.Error(w, fmt.Sprintf("upload failed: %v", err), http.StatusBadGateway)
+		http.Error(w, fmt.Sprintf("set balancer failed: %v", err), http.StatusBadGateway)
 		return
 	}
 
-	http.Redirect(w, r, "/?flash="+template.URLQueryEscaper("Uploaded "+hdr.Filename), http.StatusSeeOther)
+	http.Redirect(w, r, "/?dir="+template.URLQueryEscaper(dir)+"&flash="+template.URLQueryEscaper(resp.GetMessage()), http.StatusSeeOther)
 }
 
-func (s *server) handleDownload(w http.ResponseWriter, r *http.Request) {
-	filename := r.URL.Query().Get("filename")
-	if filename == "" {
-		http.Error(w, "filename is required", http.StatusBadRequest)
+func (s *server) handleDrain(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
 		return
 	}
-	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	dir := r.FormValue("dir")
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
-	client, conn, err := s.masterClient(ctx)
+	resp, err := s.files.Master().DrainChunkserver(ctx, &gfs.DrainChunkserverRequest{
+		ChunkserverId: r.FormValue("id"),
+		Drain:         r.FormValue("drain") == "true",
+	})
 	if err != nil {
-		http.Error(w, fmt.Sprintf("failed to connect to master: %v", err), http.StatusBadGateway)
+		http.Error(w, fmt.Sprintf("drain failed: %v", err), http.StatusBadGateway)
 		return
 	}
-	defer conn.Close()
 
-	resp, err := client.DownloadFile(ctx, &gfs.DownloadFileRequest{Filename: filename})
-	if err != nil || !resp.GetSuccess() {
-		http.Error(w, fmt.Sprintf("download failed: %v %s", err, resp.GetMessage()), http.StatusBadGateway)
+	http.Redirect(w, r, "/?dir="+template.URLQueryEscaper(dir)+"&flash="+template.URLQueryEscaper(resp.GetMessage()), http.StatusSeeOther)
+}
+
+// attachmentWriter sends the download headers just before the first byte,
+// so errors that happen earlier can still be reported with a status code
+type attachmentWriter struct {
+	w        http.ResponseWriter
+	filename string
+	started  bool
+}
+
+func (a *attachmentWriter) start() {
+	if a.started {
 		return
 	}
-	w.Header().Set("Content-Type", "application/octet-stream")
-	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
-	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write(resp.GetData())
+	a.started = true
+	a.w.Header().Set("Content-Type", "application/octet-stream")
+	a.w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", a.filename))
+	a.w.WriteHeader(http.StatusOK)
+}
+
+func (a *attachmentWriter) Write(p []byte) (int, error) {
+	a.start()
+	return a.w.Write(p)
+}
+
+func (s *server) handleDownload(w http.ResponseWriter, r *http.Request) {
+	filename := r.URL.Query().Get("filename")
+	if filename == "" {
+		http.Error(w, "filename is required", http.StatusBadRequest)
+		return
+	}
+
+	out := &attachmentWriter{w: w, filename: filename}
+	err := s.files.Download(r.Context(), filename, out)
+	switch {
+	case err == nil:
+		// Empty files never trigger a write
+		out.start()
+	case errors.Is(err, client.ErrNotFound):
+		http.Error(w, fmt.Sprintf("download failed: %v", err), http.StatusNotFound)
+	case !out.started:
+		http.Error(w, fmt.Sprintf("download failed: %v", err), http.StatusBadGateway)
+	default:
+		// Headers are already sent; all we can do is cut the response short
+		log.Printf("download of %s interrupted: %v", filename, err)
+	}
 }
 
 func main() {
@@ -282,12 +607,21 @@ func main() {
 	if masterAddr == "" {
 		masterAddr = "localhost:9000"
 	}
-	s := &server{masterAddr: masterAddr}
+	files, err := client.New(masterAddr)
+	if err != nil {
+		log.Fatalf("failed to create client: %v", err)
+	}
+	defer files.Close()
+	s := &server{masterAddr: masterAddr, files: files}
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", s.handleIndex)
 	mux.HandleFunc("/upload", s.handleUpload)
 	mux.HandleFunc("/download", s.handleDownload)
+	mux.HandleFunc("/mkdir", s.handleMkdir)
+	mux.HandleFunc("/replication", s.handleSetReplication)
+	mux.HandleFunc("/balancer", s.handleBalancer)
+	mux.HandleFunc("/drain", s.handleDrain)
 
 	addr := ":8080"
 	log.Printf("GoDFS Web listening on %s (MASTER_ADDR=%s)", addr, masterAddr)
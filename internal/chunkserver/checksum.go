@@ -0,0 +1,204 @@
+package chunkserver
+
+import (
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"sync"
+
+	"github.com/sdudhani/godfs/pkg/gfs"
+)
+
+// checksumBlockSize is the span of chunk data covered by one checksum
+const checksumBlockSize = 64 << 10
+
+// errCorruptChunk marks chunk data that does not match its checksums
+var errCorruptChunk = errors.New("chunk data does not match its checksums")
+
+// blockChecksums computes the CRC-32C checksum of every checksumBlockSize block
+// of the data written to it
+type blockChecksums struct {
+	sums   []uint32
+	crc    hash.Hash32
+	filled int   // bytes of the current block written so far
+	size   int64 // bytes written in all
+}
+
+// newBlockChecksums creates an empty checksum writer
+func newBlockChecksums() *blockChecksums {
+	return &blockChecksums{crc: gfs.NewChecksum()}
+}
+
+// Write implements io.Writer
+func (b *blockChecksums) Write(p []byte) (int, error) {
+	n := len(p)
+	b.size += int64(n)
+	for len(p) > 0 {
+		take := min(len(p), checksumBlockSize-b.filled)
+		b.crc.Write(p[:take])
+		b.filled += take
+		p = p[take:]
+
+		if b.filled == checksumBlockSize {
+			b.sums = append(b.sums, b.crc.Sum32())
+			b.crc.Reset()
+			b.filled = 0
+		}
+	}
+	return n, nil
+}
+
+// Sums returns the checksums of the blocks written so far, including a final partial one
+func (b *blockChecksums) Sums() []uint32 {
+	if b.filled == 0 {
+		return b.sums
+	}
+	return append(b.sums[:len(b.sums):len(b.sums)], b.crc.Sum32())
+}
+
+// blockCount is the number of checksum blocks in size bytes of chunk data
+func blockCount(size int64) int {
+	return int((size + checksumBlockSize - 1) / checksumBlockSize)
+}
+
+// chunkReader reads a chunk, checking the data against its block checksums
+type chunkReader struct {
+	store       Storage
+	chunkHandle string
+	size        int64
+	version     uint64
+	sums        []uint32
+}
+
+// openChunk prepares a chunk for verified reads. Chunks known to be corrupt, or
+// without an intact trailer, fail with errCorruptChunk.
+func (s *Server) openChunk(chunkHandle string) (*chunkReader, error) {
+	if s.corrupt.has(chunkHandle) {
+		return nil, fmt.Errorf("%w: chunk %s was found corrupt earlier", errCorruptChunk, chunkHandle)
+	}
+
+	meta, err := s.chunkMeta(chunkHandle)
+	if err != nil {
+		return nil, err
+	}
+	return &chunkReader{store: s.Storage, chunkHandle: chunkHandle, size: meta.size, version: meta.version, sums: meta.sums}, nil
+}
+
+// ReadAt implements io.ReaderAt. It reads whole blocks and verifies each before
+// returning any of their data.
+func (c *chunkReader) ReadAt(p []byte, off int64) (int, error) {
+	if off >= c.size {
+		return 0, io.EOF
+	}
+	end := min(off+int64(len(p)), c.size)
+
+	first := off / checksumBlockSize
+	start := first * checksumBlockSize
+	blocksEnd := min((end+checksumBlockSize-1)/checksumBlockSize*checksumBlockSize, c.size)
+
+	buf := make([]byte, blocksEnd-start)
+	if n, err := c.store.GetRange(c.chunkHandle, start, buf); n < len(buf) {
+		if err == nil || err == io.EOF {
+			// The chunk shrank after it was opened
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, err
+	}
+
+	for i := 0; i*checksumBlockSize < len(buf); i++ {
+		block := buf[i*checksumBlockSize : min((i+1)*checksumBlockSize, len(buf))]
+		if gfs.Checksum(block) != c.sums[first+int64(i)] {
+			return 0, fmt.Errorf("%w in block %d", errCorruptChunk, first+int64(i))
+		}
+	}
+
+	n := copy(p, buf[off-start:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// markCorrupt quarantines a chunk that failed verification and tells the master
+// with the next heartbeat, so it re-replicates the chunk from a good copy. It
+// reports whether the chunk was newly found corrupt.
+func (s *Server) markCorrupt(chunkHandle string, err error) bool {
+	if s.corrupt.has(chunkHandle) {
+		return false
+	}
+
+	// Holding the chunk's lock, the chunk checked is the one quarantined
+	unlock := s.chunkLocks.lock(chunkHandle)
+	defer unlock()
+
+	// A chunk rewritten while it was read can look corrupt, so check it again
+	if err := s.verifyChunk(chunkHandle, func(int) {}); !errors.Is(err, errCorruptChunk) {
+		if err == nil {
+			log.Printf("Chunk %s changed while it was read and is intact", chunkHandle)
+		}
+		return false
+	}
+	if !s.corrupt.add(chunkHandle) {
+		return false
+	}
+
+	log.Printf("Chunk %s is corrupt: %v", chunkHandle, err)
+	if err := s.quarantine(chunkHandle); err != nil {
+		log.Printf("Failed to quarantine chunk %s: %v", chunkHandle, err)
+	}
+	return true
+}
+
+// corruptChunks tracks chunks that failed verification until they are deleted or rewritten
+type corruptChunks struct {
+	mu      sync.Mutex
+	handles map[string]bool
+	unsent  []string // not reported to the master yet
+}
+
+// add marks a chunk corrupt and reports whether it was not marked already
+func (c *corruptChunks) add(chunkHandle string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.handles[chunkHandle] {
+		return false
+	}
+	if c.handles == nil {
+		c.handles = make(map[string]bool)
+	}
+	c.handles[chunkHandle] = true
+	c.unsent = append(c.unsent, chunkHandle)
+	return true
+}
+
+// has reports whether a chunk is marked corrupt
+func (c *corruptChunks) has(chunkHandle string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.handles[chunkHandle]
+}
+
+// remove forgets a chunk that was deleted or rewritten
+func (c *corruptChunks) remove(chunkHandle string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.handles, chunkHandle)
+}
+
+// take returns the corrupt chunks not reported yet and forgets them
+func (c *corruptChunks) take() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	unsent := c.unsent
+	c.unsent = nil
+	return unsent
+}
+
+// putBack returns chunks whose report could not be delivered so they go out with the next heartbeat
+func (c *corruptChunks) putBack(chunkHandles []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.unsent = append(chunkHandles, c.unsent...)
+}
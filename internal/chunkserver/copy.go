@@ -0,0 +1,63 @@
+package chunkserver
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/sdudhani/godfs/pkg/gfs"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// copyTimeout bounds copying one chunk from a peer
+const copyTimeout = 10 * time.Minute
+
+// copyChunkFrom runs a REPLICATE command: it copies a chunk at the given version
+// from the chunkserver at sourceAddress, streaming it straight to disk, and
+// returns the bytes copied
+func (s *Server) copyChunkFrom(ctx context.Context, chunkHandle string, version uint64, sourceAddress string) (int64, error) {
+	if !gfs.ValidChunkHandle(chunkHandle) {
+		return 0, fmt.Errorf("invalid chunk handle %q", chunkHandle)
+	}
+	if s.draining.Load() {
+		return 0, fmt.Errorf("chunkserver is draining and accepts no new chunks")
+	}
+
+	// A stale copy is refused before dialing; writeChunk checks again once it
+	// holds the chunk's lock
+	if err := s.checkWriteVersion(chunkHandle, version); err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, copyTimeout)
+	defer cancel()
+
+	conn, err := grpc.Dial(sourceAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return 0, fmt.Errorf("connect to chunkserver %s: %w", sourceAddress, err)
+	}
+	defer conn.Close()
+
+	// The source refuses the read if its replica is at another version
+	stream, err := gfs.NewChunkserverClient(conn).ReadChunk(ctx, &gfs.ReadChunkRequest{
+		ChunkHandle: chunkHandle,
+		Version:     version,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("read chunk %s from %s: %w", chunkHandle, sourceAddress, err)
+	}
+
+	// Stream the chunk straight to storage; a copy cut short fails instead of
+	// storing a truncated chunk
+	frames := gfs.NewChunkStreamReader(stream, 0)
+	written, err := s.writeChunk(chunkHandle, version, frames)
+	if err != nil {
+		log.Printf("Failed to copy chunk %s from %s: %v", chunkHandle, sourceAddress, err)
+		return 0, err
+	}
+
+	log.Printf("Copied chunk %s version %d from %s (%d bytes)", chunkHandle, version, sourceAddress, written)
+	return written, nil
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package chunkserver
+
+import "errors"
+
+// diskSpace is not supported on this platform
+func diskSpace(dir string) (total, free int64, err error) {
+	return 0, 0, errors.ErrUnsupported
+}
+
+// syncDir is a no-op on platforms that cannot sync directories
+func syncDir(dir string) error {
+	return nil
+}
@@ -0,0 +1,32 @@
+//go:build unix
+
+package chunkserver
+
+import (
+	"os"
+	"syscall"
+)
+
+// diskSpace returns the size of the file system holding dir and the space
+// available on it to unprivileged users
+func diskSpace(dir string) (total, free int64, err error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return 0, 0, err
+	}
+	return int64(stat.Blocks) * int64(stat.Bsize), int64(stat.Bavail) * int64(stat.Bsize), nil
+}
+
+// syncDir flushes the entries of dir to disk, so files created or renamed in it
+// survive a crash
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if closeErr := d.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,171 @@
+package chunkserver
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/sdudhani/godfs/pkg/gfs"
+)
+
+const (
+	// heartbeatInterval is how often the chunkserver checks in with the master
+	heartbeatInterval = 10 * time.Second
+
+	// chunkReportInterval is how often the full chunk list is sent to the master
+	chunkReportInterval = 60 * time.Second
+)
+
+// commandResults collects the outcomes of master commands until the next heartbeat
+type commandResults struct {
+	mu      sync.Mutex
+	results []*gfs.CommandResult
+}
+
+// add records the outcome of a command and the bytes it copied
+func (r *commandResults) add(cmd *gfs.ChunkserverCommand, size int64, err error) {
+	result := &gfs.CommandResult{CommandId: cmd.GetId(), Success: err == nil, Size: size}
+	if err != nil {
+		result.Message = err.Error()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results = append(r.results, result)
+}
+
+// take returns the collected results and forgets them
+func (r *commandResults) take() []*gfs.CommandResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	results := r.results
+	r.results = nil
+	return results
+}
+
+// putBack returns results that could not be delivered so they go out with the next heartbeat
+func (r *commandResults) putBack(results []*gfs.CommandResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results = append(results, r.results...)
+}
+
+// RunHeartbeats reports to the master under address until the process exits: a
+// chunk report right away, heartbeats that carry stats and command results, and
+// periodic full chunk reports. Commands in heartbeat responses are executed in
+// the background.
+func (s *Server) RunHeartbeats(masterClient gfs.MasterClient, address string) {
+	// Tell the master which chunks we hold right away
+	s.reportChunks(masterClient, address)
+
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	reportTicker := time.NewTicker(chunkReportInterval)
+	defer reportTicker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.sendHeartbeat(masterClient, address)
+		case <-reportTicker.C:
+			s.reportChunks(masterClient, address)
+		}
+	}
+}
+
+// sendHeartbeat sends one heartbeat and starts the commands it returns
+func (s *Server) sendHeartbeat(masterClient gfs.MasterClient, address string) {
+	// Stats are best effort; the heartbeat matters more
+	stats, err := s.Stats()
+	if err != nil {
+		log.Printf("Failed to collect chunkserver stats: %v", err)
+	}
+
+	results := s.results.take()
+	corrupt := s.corrupt.take()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	resp, err := masterClient.Heartbeat(ctx, &gfs.HeartbeatRequest{
+		ChunkserverId:  s.ID,
+		Address:        address,
+		Stats:          stats,
+		CommandResults: results,
+		Topology:       s.Topology,
+		CorruptChunks:  corrupt,
+	})
+	cancel()
+
+	if err != nil {
+		s.results.putBack(results)
+		s.corrupt.putBack(corrupt)
+		log.Printf("Failed to send heartbeat to master: %v", err)
+		return
+	}
+	log.Printf("Sent heartbeat to master")
+
+	for _, cmd := range resp.GetCommands() {
+		go func(cmd *gfs.ChunkserverCommand) {
+			size, err := s.executeCommand(masterClient, address, cmd)
+			s.results.add(cmd, size, err)
+		}(cmd)
+	}
+}
+
+// executeCommand carries out one command from the master and returns the bytes
+// it copied, if any
+func (s *Server) executeCommand(masterClient gfs.MasterClient, address string, cmd *gfs.ChunkserverCommand) (int64, error) {
+	switch cmd.GetType() {
+	case gfs.ChunkserverCommand_DELETE:
+		// Chunks the master no longer references are garbage
+		var failed int
+		for _, chunkHandle := range cmd.GetChunkHandles() {
+			resp, _ := s.DeleteChunk(context.Background(), &gfs.DeleteChunkRequest{ChunkHandle: chunkHandle})
+			if !resp.GetSuccess() {
+				failed++
+			}
+		}
+		if failed > 0 {
+			return 0, fmt.Errorf("failed to delete %d of %d chunks", failed, len(cmd.GetChunkHandles()))
+		}
+		return 0, nil
+	case gfs.ChunkserverCommand_REPLICATE:
+		// The master checks the size before it trusts the copy
+		return s.copyChunkFrom(context.Background(), cmd.GetChunkHandle(), cmd.GetVersion(), cmd.GetSourceAddress())
+	case gfs.ChunkserverCommand_REPORT:
+		// A restarted master asks for our chunks to rebuild its locations
+		return 0, s.reportChunks(masterClient, address)
+	case gfs.ChunkserverCommand_DRAIN:
+		s.draining.Store(cmd.GetDrain())
+		log.Printf("Drain mode set to %t", cmd.GetDrain())
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("unknown command type %s", cmd.GetType())
+	}
+}
+
+// reportChunks sends the full list of locally stored chunks to the master
+func (s *Server) reportChunks(masterClient gfs.MasterClient, address string) error {
+	chunks, err := s.ListChunks()
+	if err != nil {
+		log.Printf("Failed to list chunks: %v", err)
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err = masterClient.ReportChunks(ctx, &gfs.ReportChunksRequest{
+		ChunkserverId: s.ID,
+		Address:       address,
+		Chunks:        chunks,
+		Topology:      s.Topology,
+	})
+	if err != nil {
+		log.Printf("Failed to report chunks to master: %v", err)
+		return err
+	}
+	log.Printf("Reported %d chunks to master", len(chunks))
+	return nil
+}
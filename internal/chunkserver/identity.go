@@ -0,0 +1,42 @@
+package chunkserver
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// serverIDFileName names the object holding the chunkserver's identity
+const serverIDFileName = "server_id"
+
+// loadOrCreateServerID returns the ID persisted in store, generating and
+// storing a new one the first time the storage is used. The ID stays the
+// same across restarts and address changes, so the master can recognize the
+// chunkserver and the chunks it holds.
+func loadOrCreateServerID(store Storage) (string, error) {
+	data, err := store.Get(serverIDFileName)
+	if err == nil {
+		id := strings.TrimSpace(string(data))
+		if id == "" {
+			return "", fmt.Errorf("%s is empty", serverIDFileName)
+		}
+		return id, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("read server ID: %w", err)
+	}
+
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate server ID: %w", err)
+	}
+	id := "cs-" + hex.EncodeToString(buf)
+
+	if _, err := store.Put(serverIDFileName, strings.NewReader(id+"\n")); err != nil {
+		return "", fmt.Errorf("write server ID: %w", err)
+	}
+	return id, nil
+}
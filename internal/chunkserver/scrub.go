@@ -0,0 +1,183 @@
+package chunkserver
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sdudhani/godfs/pkg/gfs"
+)
+
+const (
+	// DefaultScrubRate is the bytes per second the scrubber reads when
+	// Server.ScrubRate is unset
+	DefaultScrubRate = 8 << 20
+
+	// scrubPassInterval is how long the scrubber rests between passes over all chunks
+	scrubPassInterval = 24 * time.Hour
+
+	// scrubReadSize is how much of a chunk the scrubber verifies at a time
+	scrubReadSize = 1 << 20
+
+	// quarantinePrefix starts the names of corrupt chunks kept for inspection
+	quarantinePrefix = "quarantine/"
+)
+
+// scrubState is the scrubber's progress, reported with heartbeat stats
+type scrubState struct {
+	mu             sync.Mutex
+	started        bool
+	running        bool
+	scrubbedChunks int32
+	totalChunks    int32
+	scrubbedBytes  int64
+	totalBytes     int64
+	lastScrub      int64 // Unix seconds
+	corruptChunks  int64
+}
+
+// status returns the scrubber's progress, or nil if it has not started yet
+func (st *scrubState) status() *gfs.ScrubStatus {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if !st.started {
+		return nil
+	}
+	return &gfs.ScrubStatus{
+		Running:        st.running,
+		ScrubbedChunks: st.scrubbedChunks,
+		TotalChunks:    st.totalChunks,
+		ScrubbedBytes:  st.scrubbedBytes,
+		TotalBytes:     st.totalBytes,
+		LastScrub:      st.lastScrub,
+		CorruptChunks:  st.corruptChunks,
+	}
+}
+
+// RunScrubber verifies the checksums of every chunk in the background until the
+// process exits, reading at most ScrubRate bytes per second. Corrupt chunks are
+// quarantined and reported to the master. A pass starts right away and then
+// once every scrubPassInterval.
+func (s *Server) RunScrubber() {
+	for {
+		s.scrubPass()
+		time.Sleep(scrubPassInterval)
+	}
+}
+
+// scrubPass verifies every chunk once
+func (s *Server) scrubPass() {
+	chunks, err := s.ListChunks()
+	if err != nil {
+		log.Printf("Failed to list chunks to scrub: %v", err)
+		return
+	}
+
+	var totalBytes int64
+	for _, chunk := range chunks {
+		totalBytes += chunk.GetSize()
+	}
+
+	st := &s.scrub
+	st.mu.Lock()
+	st.started, st.running = true, true
+	st.scrubbedChunks, st.totalChunks = 0, int32(len(chunks))
+	st.scrubbedBytes, st.totalBytes = 0, totalBytes
+	st.mu.Unlock()
+
+	rate := s.ScrubRate
+	if rate <= 0 {
+		rate = DefaultScrubRate
+	}
+
+	// Sleep whenever reading got ahead of the rate
+	start := time.Now()
+	var read int64
+	pace := func(n int) {
+		read += int64(n)
+		if ahead := time.Duration(float64(read)/float64(rate)*float64(time.Second)) - time.Since(start); ahead > 0 {
+			time.Sleep(ahead)
+		}
+	}
+
+	var corrupt int64
+	for _, chunk := range chunks {
+		chunkHandle := chunk.GetChunkHandle()
+
+		// Chunks deleted since the listing are skipped
+		err := s.verifyChunk(chunkHandle, pace)
+		switch {
+		case errors.Is(err, errCorruptChunk):
+			if s.markCorrupt(chunkHandle, err) {
+				corrupt++
+			}
+		case err != nil && !errors.Is(err, os.ErrNotExist):
+			log.Printf("Failed to scrub chunk %s: %v", chunkHandle, err)
+		}
+
+		st.mu.Lock()
+		st.scrubbedChunks++
+		st.scrubbedBytes += chunk.GetSize()
+		st.mu.Unlock()
+	}
+
+	st.mu.Lock()
+	st.running = false
+	st.lastScrub = time.Now().Unix()
+	st.corruptChunks += corrupt
+	st.mu.Unlock()
+
+	log.Printf("Scrubbed %d chunks (%d bytes) in %s, %d corrupt",
+		len(chunks), totalBytes, time.Since(start).Round(time.Second), corrupt)
+}
+
+// verifyChunk reads a whole chunk, checking every block against its checksum,
+// and calls pace with the bytes read after each read
+func (s *Server) verifyChunk(chunkHandle string, pace func(n int)) error {
+	chunk, err := s.openChunk(chunkHandle)
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, scrubReadSize)
+	for offset := int64(0); offset < chunk.size; {
+		n, err := chunk.ReadAt(buf, offset)
+		offset += int64(n)
+		pace(n)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// quarantine moves a corrupt chunk under quarantinePrefix. It is kept for
+// inspection, but never served or reported again. Callers must hold the
+// chunk's lock.
+func (s *Server) quarantine(chunkHandle string) error {
+	// A corrupt chunk no longer counts as stored, even if it cannot be moved
+	s.stored.remove(chunkHandle)
+
+	info, err := s.Storage.Stat(chunkHandle)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("quarantine %s: %w", chunkHandle, err)
+	}
+
+	if _, err := s.Storage.Put(quarantinePrefix+chunkHandle, objectReader(s.Storage, chunkHandle, info.Size)); err != nil {
+		return fmt.Errorf("quarantine %s: %w", chunkHandle, err)
+	}
+	if err := s.Storage.Delete(chunkHandle); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("quarantine %s: %w", chunkHandle, err)
+	}
+	return nil
+}
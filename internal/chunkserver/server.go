@@ -1,31 +1,121 @@
 package chunkserver
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
-	"path/filepath"
+	"sync"
+	"sync/atomic"
 
 	"github.com/sdudhani/godfs/pkg/gfs"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // Server implements the gRPC Chunkserver server
 type Server struct {
 	gfs.UnimplementedChunkserverServer
-	DataDir string // Directory to store chunks
+	Storage Storage // Where chunks are kept
+	ID      string  // Stable identity, persisted in Storage
+
+	// Failure domains reported to the master for replica placement
+	Topology *gfs.Topology
+
+	activeOps atomic.Int64 // RPCs in progress
+	draining  atomic.Bool  // Set by the master; new chunks are refused while draining
+
+	// Outcomes of master commands, sent with the next heartbeat
+	results commandResults
+
+	// Bytes per second the background scrubber reads; DefaultScrubRate if unset
+	ScrubRate int64
+
+	// Chunks that failed verification; reported with the next heartbeat
+	corrupt corruptChunks
+
+	// Sizes of the chunks held, so heartbeats need not list the storage
+	stored storedChunks
+
+	scrub scrubState
+
+	// Writes, deletes and quarantines of the same chunk take turns
+	chunkLocks chunkLocks
+}
+
+// chunkLocks hands out a lock per chunk handle, so a chunk is only changed by
+// one operation at a time
+type chunkLocks struct {
+	mu    sync.Mutex
+	locks map[string]*chunkLock
+}
+
+// chunkLock is the lock of one chunk
+type chunkLock struct {
+	sync.Mutex
+	users int // goroutines holding or waiting for the lock
+}
+
+// lock locks a chunk and returns the function that unlocks it
+func (l *chunkLocks) lock(chunkHandle string) (unlock func()) {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*chunkLock)
+	}
+	cl := l.locks[chunkHandle]
+	if cl == nil {
+		cl = &chunkLock{}
+		l.locks[chunkHandle] = cl
+	}
+	cl.users++
+	l.mu.Unlock()
+
+	cl.Lock()
+	return func() {
+		cl.Unlock()
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if cl.users--; cl.users == 0 {
+			delete(l.locks, chunkHandle)
+		}
+	}
 }
 
-// NewServer creates a new chunkserver instance
+// NewServer creates a new chunkserver instance that keeps each chunk in a file
+// under dataDir
 func NewServer(dataDir string) *Server {
-	// Create data directory if it doesn't exist
-	if err := os.MkdirAll(dataDir, 0755); err != nil {
-		log.Fatalf("Failed to create data directory: %v", err)
+	store, err := NewFileStorage(dataDir)
+	if err != nil {
+		log.Fatalf("Failed to open data directory: %v", err)
 	}
+	return NewServerWithStorage(store)
+}
 
-	return &Server{
-		DataDir: dataDir,
+// NewServerWithStorage creates a new chunkserver instance that keeps its chunks in store
+func NewServerWithStorage(store Storage) *Server {
+	id, err := loadOrCreateServerID(store)
+	if err != nil {
+		log.Fatalf("Failed to load chunkserver ID: %v", err)
 	}
+
+	s := &Server{
+		Storage: store,
+		ID:      id,
+	}
+
+	// Later writes, deletes and quarantines keep the counts up to date
+	chunks, err := s.ListChunks()
+	if err != nil {
+		log.Fatalf("Failed to list chunks: %v", err)
+	}
+	for _, chunk := range chunks {
+		s.stored.set(chunk.GetChunkHandle(), chunk.GetSize())
+	}
+	return s
 }
 
 // StoreChunk stores a chunk of data
@@ -33,11 +123,23 @@ func (s *Server) StoreChunk(ctx context.Context, req *gfs.StoreChunkRequest) (*g
 	chunkHandle := req.GetChunkHandle()
 	data := req.GetData()
 
-	// Create file path for this chunk
-	chunkPath := filepath.Join(s.DataDir, chunkHandle)
+	// Handles become file names, so only the canonical format is accepted
+	if !gfs.ValidChunkHandle(chunkHandle) {
+		return &gfs.StoreChunkResponse{
+			Success: false,
+			Message: fmt.Sprintf("Invalid chunk handle %q", chunkHandle),
+		}, nil
+	}
+
+	if s.draining.Load() {
+		return &gfs.StoreChunkResponse{
+			Success: false,
+			Message: "Chunkserver is draining and accepts no new chunks",
+		}, nil
+	}
 
-	// Write data to file
-	if err := os.WriteFile(chunkPath, data, 0644); err != nil {
+	_, err := s.writeChunk(chunkHandle, req.GetVersion(), bytes.NewReader(data))
+	if err != nil {
 		log.Printf("Failed to store chunk %s: %v", chunkHandle, err)
 		return &gfs.StoreChunkResponse{
 			Success: false,
@@ -45,7 +147,7 @@ func (s *Server) StoreChunk(ctx context.Context, req *gfs.StoreChunkRequest) (*g
 		}, nil
 	}
 
-	log.Printf("Stored chunk %s (%d bytes)", chunkHandle, len(data))
+	log.Printf("Stored chunk %s version %d (%d bytes)", chunkHandle, req.GetVersion(), len(data))
 	return &gfs.StoreChunkResponse{
 		Success: true,
 		Message: "Chunk stored successfully",
@@ -56,11 +158,16 @@ func (s *Server) StoreChunk(ctx context.Context, req *gfs.StoreChunkRequest) (*g
 func (s *Server) RetrieveChunk(ctx context.Context, req *gfs.RetrieveChunkRequest) (*gfs.RetrieveChunkResponse, error) {
 	chunkHandle := req.GetChunkHandle()
 
-	// Create file path for this chunk
-	chunkPath := filepath.Join(s.DataDir, chunkHandle)
+	if !gfs.ValidChunkHandle(chunkHandle) {
+		return &gfs.RetrieveChunkResponse{
+			Success: false,
+			Data:    nil,
+			Message: fmt.Sprintf("Invalid chunk handle %q", chunkHandle),
+		}, nil
+	}
 
-	// Read data from file
-	data, err := os.ReadFile(chunkPath)
+	// Read the data, verifying every block
+	data, err := s.readChunkData(chunkHandle)
 	if err != nil {
 		log.Printf("Failed to retrieve chunk %s: %v", chunkHandle, err)
 		return &gfs.RetrieveChunkResponse{
@@ -82,11 +189,19 @@ func (s *Server) RetrieveChunk(ctx context.Context, req *gfs.RetrieveChunkReques
 func (s *Server) DeleteChunk(ctx context.Context, req *gfs.DeleteChunkRequest) (*gfs.DeleteChunkResponse, error) {
 	chunkHandle := req.GetChunkHandle()
 
-	// Create file path for this chunk
-	chunkPath := filepath.Join(s.DataDir, chunkHandle)
+	if !gfs.ValidChunkHandle(chunkHandle) {
+		return &gfs.DeleteChunkResponse{
+			Success: false,
+			Message: fmt.Sprintf("Invalid chunk handle %q", chunkHandle),
+		}, nil
+	}
 
-	// Delete file
-	if err := os.Remove(chunkPath); err != nil {
+	unlock := s.chunkLocks.lock(chunkHandle)
+	defer unlock()
+
+	// A chunk that is gone already, e.g. quarantined or deleted by an earlier
+	// command whose result was lost, counts as deleted
+	if err := s.Storage.Delete(chunkHandle); err != nil && !errors.Is(err, os.ErrNotExist) {
 		log.Printf("Failed to delete chunk %s: %v", chunkHandle, err)
 		return &gfs.DeleteChunkResponse{
 			Success: false,
@@ -94,9 +209,229 @@ func (s *Server) DeleteChunk(ctx context.Context, req *gfs.DeleteChunkRequest) (
 		}, nil
 	}
 
+	s.corrupt.remove(chunkHandle)
+	s.stored.remove(chunkHandle)
+
 	log.Printf("Deleted chunk %s", chunkHandle)
 	return &gfs.DeleteChunkResponse{
 		Success: true,
 		Message: "Chunk deleted successfully",
 	}, nil
 }
+
+// WriteChunk stores a chunk received as a stream of frames
+func (s *Server) WriteChunk(stream gfs.Chunkserver_WriteChunkServer) error {
+	// The first frame names the chunk
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return stream.SendAndClose(&gfs.StoreChunkResponse{
+			Success: false,
+			Message: "Empty chunk stream",
+		})
+	}
+	if err != nil {
+		return err
+	}
+	chunkHandle := first.GetChunkHandle()
+
+	if !gfs.ValidChunkHandle(chunkHandle) {
+		return stream.SendAndClose(&gfs.StoreChunkResponse{
+			Success: false,
+			Message: fmt.Sprintf("Invalid chunk handle %q", chunkHandle),
+		})
+	}
+
+	if s.draining.Load() {
+		return stream.SendAndClose(&gfs.StoreChunkResponse{
+			Success: false,
+			Message: "Chunkserver is draining and accepts no new chunks",
+		})
+	}
+
+	frames := &frameReader{data: first.GetData(), recv: func() ([]byte, error) {
+		frame, err := stream.Recv()
+		return frame.GetData(), err
+	}}
+	written, err := s.writeChunk(chunkHandle, first.GetVersion(), frames)
+	if err != nil {
+		log.Printf("Failed to store chunk %s: %v", chunkHandle, err)
+		return stream.SendAndClose(&gfs.StoreChunkResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to store chunk: %v", err),
+		})
+	}
+
+	log.Printf("Stored chunk %s version %d (%d bytes, streamed)", chunkHandle, first.GetVersion(), written)
+	return stream.SendAndClose(&gfs.StoreChunkResponse{
+		Success: true,
+		Message: "Chunk stored successfully",
+	})
+}
+
+// frameReader reads the data of a stream of frames. recv returns the data of
+// the next frame, or io.EOF after the last one.
+type frameReader struct {
+	recv func() ([]byte, error)
+	data []byte // rest of the current frame
+}
+
+// Read implements io.Reader
+func (r *frameReader) Read(p []byte) (int, error) {
+	for len(r.data) == 0 {
+		data, err := r.recv()
+		if err != nil {
+			return 0, err
+		}
+		r.data = data
+	}
+
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+// writeChunk stores what r yields as a chunk at version, with a trailer holding
+// its version and block checksums, and returns the bytes of data written. The
+// chunk replaces any older copy in one step, so a failed write leaves the old
+// chunk as it was. A write of an older version than the stored one is refused
+// before r is read.
+func (s *Server) writeChunk(chunkHandle string, version uint64, r io.Reader) (int64, error) {
+	unlock := s.chunkLocks.lock(chunkHandle)
+	defer unlock()
+
+	// Never let an older version overwrite a newer one
+	if err := s.checkWriteVersion(chunkHandle, version); err != nil {
+		return 0, err
+	}
+
+	sums := newBlockChecksums()
+	if _, err := s.Storage.Put(chunkHandle, &chunkObject{data: r, sums: sums, version: version}); err != nil {
+		return sums.size, err
+	}
+
+	// A rewritten chunk is no longer corrupt
+	s.corrupt.remove(chunkHandle)
+	s.stored.set(chunkHandle, sums.size)
+	return sums.size, nil
+}
+
+// ReadChunk streams a chunk back in frames, starting at the requested offset
+func (s *Server) ReadChunk(req *gfs.ReadChunkRequest, stream gfs.Chunkserver_ReadChunkServer) error {
+	chunkHandle := req.GetChunkHandle()
+
+	if !gfs.ValidChunkHandle(chunkHandle) {
+		return status.Errorf(codes.InvalidArgument, "invalid chunk handle %q", chunkHandle)
+	}
+
+	chunk, err := s.openChunk(chunkHandle)
+	if errors.Is(err, errCorruptChunk) {
+		s.markCorrupt(chunkHandle, err)
+		return status.Errorf(codes.DataLoss, "failed to retrieve chunk: %v", err)
+	}
+	if err != nil {
+		log.Printf("Failed to retrieve chunk %s: %v", chunkHandle, err)
+		return status.Errorf(codes.NotFound, "failed to retrieve chunk: %v", err)
+	}
+
+	// Refuse to serve a replica whose version differs from what the reader expects
+	if req.GetVersion() != 0 && chunk.version != req.GetVersion() {
+		return status.Errorf(codes.FailedPrecondition, "chunk %s is at version %d, not %d", chunkHandle, chunk.version, req.GetVersion())
+	}
+
+	if req.GetOffset() < 0 {
+		return status.Errorf(codes.InvalidArgument, "invalid offset %d", req.GetOffset())
+	}
+
+	// Every block is verified before any of it is sent, so readers never see
+	// corrupt data and can resume from another replica
+	var sent int64
+	buf := make([]byte, gfs.StreamFrameSize)
+
+	// The first frame announces the chunk size so readers can tell when the
+	// stream ended early
+	frame := &gfs.ReadChunkResponse{ChunkSize: &chunk.size}
+	for offset := req.GetOffset(); ; {
+		n, err := chunk.ReadAt(buf, offset)
+		if n > 0 {
+			frame.Data = buf[:n]
+			if sendErr := stream.Send(frame); sendErr != nil {
+				return sendErr
+			}
+			frame = &gfs.ReadChunkResponse{}
+			sent += int64(n)
+			offset += int64(n)
+		}
+		if err == io.EOF {
+			break
+		}
+		if errors.Is(err, errCorruptChunk) {
+			s.markCorrupt(chunkHandle, err)
+			return status.Errorf(codes.DataLoss, "failed to read chunk: %v", err)
+		}
+		if err != nil {
+			log.Printf("Failed to read chunk %s: %v", chunkHandle, err)
+			return status.Errorf(codes.Internal, "failed to read chunk: %v", err)
+		}
+	}
+
+	// Nothing lies at or past the offset, but the size must still be announced
+	if frame.ChunkSize != nil {
+		if err := stream.Send(frame); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("Retrieved chunk %s (%d bytes from offset %d, streamed)", chunkHandle, sent, req.GetOffset())
+	return nil
+}
+
+// ListChunks returns every chunk in storage
+func (s *Server) ListChunks() ([]*gfs.ChunkReport, error) {
+	objects, err := s.Storage.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var chunks []*gfs.ChunkReport
+	for _, object := range objects {
+		// Only objects named like chunk handles are chunks
+		if !gfs.ValidChunkHandle(object.Name) {
+			continue
+		}
+
+		// The master dropped corrupt chunks and must not count them again
+		if s.corrupt.has(object.Name) {
+			continue
+		}
+
+		meta, err := s.chunkMeta(object.Name)
+		if err != nil {
+			log.Printf("Skipping chunk %s in report: %v", object.Name, err)
+			continue
+		}
+
+		chunks = append(chunks, &gfs.ChunkReport{
+			ChunkHandle: object.Name,
+			Size:        meta.size,
+			Version:     meta.version,
+		})
+	}
+
+	return chunks, nil
+}
+
+// readChunkData reads a whole chunk, verifying every block. A chunk that fails
+// verification is marked corrupt.
+func (s *Server) readChunkData(chunkHandle string) ([]byte, error) {
+	chunk, err := s.openChunk(chunkHandle)
+	if err == nil {
+		data := make([]byte, chunk.size)
+		if _, err = io.ReadFull(io.NewSectionReader(chunk, 0, chunk.size), data); err == nil {
+			return data, nil
+		}
+	}
+	if errors.Is(err, errCorruptChunk) {
+		s.markCorrupt(chunkHandle, err)
+	}
+	return nil, err
+}
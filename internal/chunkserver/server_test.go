@@ -0,0 +1,229 @@
+package chunkserver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"reflect"
+	"testing"
+
+	"github.com/sdudhani/godfs/pkg/gfs"
+	"google.golang.org/grpc"
+)
+
+// newTestServer creates a chunkserver keeping its chunks in memory
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	return NewServerWithStorage(NewMemoryStorage())
+}
+
+// storeChunk stores data as a chunk at version and fails the test if that does not work
+func storeChunk(t *testing.T, s *Server, chunkHandle string, version uint64, data []byte) {
+	t.Helper()
+	resp, err := s.StoreChunk(context.Background(), &gfs.StoreChunkRequest{ChunkHandle: chunkHandle, Version: version, Data: data})
+	if err != nil || !resp.GetSuccess() {
+		t.Fatalf("StoreChunk(%s) = %v, %v", chunkHandle, resp.GetMessage(), err)
+	}
+}
+
+// readStream collects the frames ReadChunk sends
+type readStream struct {
+	grpc.ServerStream
+	data []byte
+	size int64
+}
+
+// Send implements gfs.Chunkserver_ReadChunkServer
+func (r *readStream) Send(frame *gfs.ReadChunkResponse) error {
+	if frame.ChunkSize != nil {
+		r.size = frame.GetChunkSize()
+	}
+	r.data = append(r.data, frame.GetData()...)
+	return nil
+}
+
+func TestWriteAndRead(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+	}{
+		{name: "empty", size: 0},
+		{name: "shorter than the trailer", size: 3},
+		{name: "one block", size: checksumBlockSize},
+		{name: "partial last block", size: 2*checksumBlockSize + 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t)
+			chunkHandle := gfs.FormatChunkHandle(1)
+			data := bytes.Repeat([]byte("godfs"), tt.size/5+1)[:tt.size]
+			storeChunk(t, s, chunkHandle, 7, data)
+
+			resp, err := s.RetrieveChunk(context.Background(), &gfs.RetrieveChunkRequest{ChunkHandle: chunkHandle})
+			if err != nil || !resp.GetSuccess() || !bytes.Equal(resp.GetData(), data) {
+				t.Fatalf("RetrieveChunk() returned %d bytes (%s), want %d", len(resp.GetData()), resp.GetMessage(), len(data))
+			}
+
+			// A read starting past the first block still announces the whole size
+			offset := min(int64(tt.size), checksumBlockSize+1)
+			stream := &readStream{}
+			if err := s.ReadChunk(&gfs.ReadChunkRequest{ChunkHandle: chunkHandle, Version: 7, Offset: offset}, stream); err != nil {
+				t.Fatalf("ReadChunk() error = %v", err)
+			}
+			if !bytes.Equal(stream.data, data[offset:]) || stream.size != int64(tt.size) {
+				t.Fatalf("ReadChunk() sent %d bytes of a %d-byte chunk, want %d of %d", len(stream.data), stream.size, len(data)-int(offset), tt.size)
+			}
+
+			chunks, err := s.ListChunks()
+			want := []*gfs.ChunkReport{{ChunkHandle: chunkHandle, Size: int64(tt.size), Version: 7}}
+			if err != nil || len(chunks) != 1 || chunks[0].String() != want[0].String() {
+				t.Fatalf("ListChunks() = %v, %v; want %v", chunks, err, want)
+			}
+		})
+	}
+}
+
+func TestWriteVersions(t *testing.T) {
+	s := newTestServer(t)
+	chunkHandle := gfs.FormatChunkHandle(1)
+	storeChunk(t, s, chunkHandle, 2, []byte("new"))
+
+	resp, _ := s.StoreChunk(context.Background(), &gfs.StoreChunkRequest{ChunkHandle: chunkHandle, Version: 1, Data: []byte("old")})
+	if resp.GetSuccess() {
+		t.Fatal("StoreChunk() accepted an older version")
+	}
+	if err := s.ReadChunk(&gfs.ReadChunkRequest{ChunkHandle: chunkHandle, Version: 1}, &readStream{}); err == nil {
+		t.Fatal("ReadChunk() served version 2 to a reader expecting version 1")
+	}
+
+	stream := &readStream{}
+	if err := s.ReadChunk(&gfs.ReadChunkRequest{ChunkHandle: chunkHandle, Version: 2}, stream); err != nil || string(stream.data) != "new" {
+		t.Fatalf("ReadChunk() = %q, %v; want %q", stream.data, err, "new")
+	}
+}
+
+func TestCorruptChunkIsQuarantined(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(object []byte)
+	}{
+		{name: "data", corrupt: func(object []byte) { object[10] ^= 1 }},
+		{name: "trailer", corrupt: func(object []byte) { object[len(object)-12] ^= 1 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t)
+			chunkHandle := gfs.FormatChunkHandle(1)
+			storeChunk(t, s, chunkHandle, 1, bytes.Repeat([]byte{1}, 1000))
+
+			object, _ := s.Storage.Get(chunkHandle)
+			tt.corrupt(object)
+			s.Storage.Put(chunkHandle, bytes.NewReader(object))
+
+			resp, _ := s.RetrieveChunk(context.Background(), &gfs.RetrieveChunkRequest{ChunkHandle: chunkHandle})
+			if resp.GetSuccess() {
+				t.Fatal("RetrieveChunk() served a corrupt chunk")
+			}
+
+			if _, err := s.Storage.Stat(chunkHandle); !errors.Is(err, fs.ErrNotExist) {
+				t.Fatalf("corrupt chunk is still in place: %v", err)
+			}
+			if quarantined, err := s.Storage.Get(quarantinePrefix + chunkHandle); err != nil || !bytes.Equal(quarantined, object) {
+				t.Fatalf("quarantined chunk = %d bytes, %v; want the %d corrupt bytes", len(quarantined), err, len(object))
+			}
+			if got := s.corrupt.take(); !reflect.DeepEqual(got, []string{chunkHandle}) {
+				t.Fatalf("corrupt chunks to report = %v, want [%s]", got, chunkHandle)
+			}
+			if chunks, _ := s.ListChunks(); len(chunks) != 0 {
+				t.Fatalf("ListChunks() = %v after quarantine, want none", chunks)
+			}
+
+			// Rewriting the chunk makes it servable again
+			storeChunk(t, s, chunkHandle, 2, []byte("fresh"))
+			if resp, _ := s.RetrieveChunk(context.Background(), &gfs.RetrieveChunkRequest{ChunkHandle: chunkHandle}); string(resp.GetData()) != "fresh" {
+				t.Fatalf("RetrieveChunk() after rewrite = %q (%s), want %q", resp.GetData(), resp.GetMessage(), "fresh")
+			}
+		})
+	}
+}
+
+func TestDeleteChunk(t *testing.T) {
+	s := newTestServer(t)
+	stored, quarantined, missing := gfs.FormatChunkHandle(1), gfs.FormatChunkHandle(2), gfs.FormatChunkHandle(3)
+	storeChunk(t, s, stored, 1, []byte("data"))
+	storeChunk(t, s, quarantined, 1, []byte("data"))
+	s.Storage.Put(quarantined, bytes.NewReader([]byte("garbage that has no trailer")))
+	s.RetrieveChunk(context.Background(), &gfs.RetrieveChunkRequest{ChunkHandle: quarantined})
+	if !s.corrupt.has(quarantined) {
+		t.Fatal("chunk without a trailer was not quarantined")
+	}
+
+	// Deleting is idempotent, so a repeated DELETE command succeeds
+	for _, chunkHandle := range []string{stored, quarantined, missing, stored} {
+		if resp, _ := s.DeleteChunk(context.Background(), &gfs.DeleteChunkRequest{ChunkHandle: chunkHandle}); !resp.GetSuccess() {
+			t.Fatalf("DeleteChunk(%s) failed: %s", chunkHandle, resp.GetMessage())
+		}
+	}
+
+	objects, _ := s.Storage.List()
+	for _, object := range objects {
+		if object.Name == stored || object.Name == quarantined {
+			t.Fatalf("%s is still stored after the delete", object.Name)
+		}
+	}
+	if chunks, _ := s.ListChunks(); len(chunks) != 0 {
+		t.Fatalf("ListChunks() = %v after deleting every chunk, want none", chunks)
+	}
+}
+
+func TestStatsFollowChunks(t *testing.T) {
+	store := NewMemoryStorage()
+	s := NewServerWithStorage(store)
+	kept, replaced, deleted, corrupt := gfs.FormatChunkHandle(1), gfs.FormatChunkHandle(2), gfs.FormatChunkHandle(3), gfs.FormatChunkHandle(4)
+	storeChunk(t, s, kept, 1, make([]byte, 100))
+	storeChunk(t, s, replaced, 1, make([]byte, 50))
+	storeChunk(t, s, deleted, 1, make([]byte, 20))
+	storeChunk(t, s, corrupt, 1, make([]byte, 10))
+
+	storeChunk(t, s, replaced, 2, make([]byte, 70))
+	s.DeleteChunk(context.Background(), &gfs.DeleteChunkRequest{ChunkHandle: deleted})
+	s.Storage.Put(corrupt, bytes.NewReader([]byte("garbage that has no trailer")))
+	s.RetrieveChunk(context.Background(), &gfs.RetrieveChunkRequest{ChunkHandle: corrupt})
+
+	// Counts match the chunks listed, also for a server started on the same storage
+	for _, server := range []*Server{s, NewServerWithStorage(store)} {
+		stats, err := server.Stats()
+		if err != nil || stats.GetChunkCount() != 2 || stats.GetUsedBytes() != 170 {
+			t.Fatalf("Stats() = %d chunks of %d bytes, %v; want 2 of 170", stats.GetChunkCount(), stats.GetUsedBytes(), err)
+		}
+	}
+}
+
+func TestMemoryStorageGetRange(t *testing.T) {
+	store := NewMemoryStorage()
+	store.Put("object", bytes.NewReader([]byte("0123456789")))
+
+	tests := []struct {
+		offset  int64
+		size    int
+		want    string
+		wantErr bool
+	}{
+		{offset: 0, size: 4, want: "0123"},
+		{offset: 6, size: 4, want: "6789"},
+		{offset: 8, size: 4, want: "89", wantErr: true},
+		{offset: 10, size: 4, wantErr: true},
+		{offset: -1, size: 4, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		p := make([]byte, tt.size)
+		n, err := store.GetRange("object", tt.offset, p)
+		if string(p[:n]) != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("GetRange(%d, %d) = %q, %v; want %q, error %v", tt.offset, tt.size, p[:n], err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package chunkserver
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/sdudhani/godfs/pkg/gfs"
+	"google.golang.org/grpc"
+)
+
+// Stats summarizes the storage and load of this chunkserver for the master
+func (s *Server) Stats() (*gfs.ChunkserverStats, error) {
+	chunks, used := s.stored.totals()
+
+	// Disk space is best effort; not every platform or storage can report it
+	var total, free int64
+	if space, ok := s.Storage.(spaceReporter); ok {
+		var err error
+		if total, free, err = space.Space(); err != nil {
+			log.Printf("Failed to get disk space: %v", err)
+		}
+	}
+
+	return &gfs.ChunkserverStats{
+		ChunkCount:       int32(chunks),
+		UsedBytes:        used,
+		FreeBytes:        free,
+		TotalBytes:       total,
+		ActiveOperations: int32(s.activeOps.Load()),
+		Draining:         s.draining.Load(),
+		Scrub:            s.scrub.status(),
+	}, nil
+}
+
+// UnaryInterceptor counts unary RPCs in progress as load
+func (s *Server) UnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	s.activeOps.Add(1)
+	defer s.activeOps.Add(-1)
+	return handler(ctx, req)
+}
+
+// StreamInterceptor counts streaming RPCs in progress as load
+func (s *Server) StreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	s.activeOps.Add(1)
+	defer s.activeOps.Add(-1)
+	return handler(srv, ss)
+}
+
+// storedChunks tracks the data size of every chunk held, with running totals
+type storedChunks struct {
+	mu    sync.Mutex
+	sizes map[string]int64
+	bytes int64
+}
+
+// set records that a chunk holds size bytes, replacing any earlier size
+func (c *storedChunks) set(chunkHandle string, size int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.sizes == nil {
+		c.sizes = make(map[string]int64)
+	}
+	c.bytes += size - c.sizes[chunkHandle]
+	c.sizes[chunkHandle] = size
+}
+
+// remove forgets a chunk that was deleted or quarantined
+func (c *storedChunks) remove(chunkHandle string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.bytes -= c.sizes[chunkHandle]
+	delete(c.sizes, chunkHandle)
+}
+
+// totals returns the number of chunks held and their bytes of data
+func (c *storedChunks) totals() (int, int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.sizes), c.bytes
+}
@@ -0,0 +1,57 @@
+package chunkserver
+
+import "io"
+
+// Storage keeps the objects of a chunkserver: chunks and the server ID. Names
+// are slash-separated paths such as a chunk handle or "quarantine/<chunk
+// handle>". Operations on missing objects fail with an error matching
+// fs.ErrNotExist. Implementations must be safe for concurrent use.
+type Storage interface {
+	// Put stores what r yields under name, replacing any previous object in one
+	// step. If it fails, the previous object is left as it was.
+	Put(name string, r io.Reader) (int64, error)
+
+	// Get returns the contents of an object
+	Get(name string) ([]byte, error)
+
+	// GetRange reads len(p) bytes of an object starting at offset, with the
+	// semantics of io.ReaderAt
+	GetRange(name string, offset int64, p []byte) (int, error)
+
+	// Delete removes an object
+	Delete(name string) error
+
+	// List returns every object
+	List() ([]ObjectInfo, error)
+
+	// Stat describes an object
+	Stat(name string) (ObjectInfo, error)
+}
+
+// ObjectInfo describes an object in a Storage
+type ObjectInfo struct {
+	Name string
+	Size int64
+}
+
+// spaceReporter is implemented by storage that knows the size of the device it
+// uses and the space left on it
+type spaceReporter interface {
+	Space() (total, free int64, err error)
+}
+
+// objectReader reads an object of the given size through GetRange
+func objectReader(store Storage, name string, size int64) *io.SectionReader {
+	return io.NewSectionReader(objectReaderAt{store, name}, 0, size)
+}
+
+// objectReaderAt adapts an object to io.ReaderAt
+type objectReaderAt struct {
+	store Storage
+	name  string
+}
+
+// ReadAt implements io.ReaderAt
+func (r objectReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	return r.store.GetRange(r.name, off, p)
+}
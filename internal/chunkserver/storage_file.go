@@ -0,0 +1,190 @@
+package chunkserver
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// tempSuffix marks files still being written; they are renamed into place once
+// complete and removed at startup if a crash interrupted them
+const tempSuffix = ".tmp"
+
+// FileStorage keeps every object in its own file under a directory. Objects are
+// written to a temporary file, flushed to disk and renamed into place, so a
+// crash never leaves a partial object behind.
+type FileStorage struct {
+	dir string
+}
+
+// NewFileStorage creates dir if needed and removes temporary files left behind
+// by writes a crash interrupted
+func NewFileStorage(dir string) (*FileStorage, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create data directory: %w", err)
+	}
+
+	s := &FileStorage{dir: dir}
+	if err := s.removeTempFiles(); err != nil {
+		return nil, fmt.Errorf("clean up data directory: %w", err)
+	}
+	return s, nil
+}
+
+// path returns the file holding an object, refusing names that leave the directory
+func (s *FileStorage) path(name string) (string, error) {
+	if !filepath.IsLocal(filepath.FromSlash(name)) {
+		return "", fmt.Errorf("invalid object name %q", name)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(name)), nil
+}
+
+// Put implements Storage
+func (s *FileStorage) Put(name string, r io.Reader) (int64, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return 0, err
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, err
+	}
+
+	file, err := os.CreateTemp(dir, filepath.Base(path)+".*"+tempSuffix)
+	if err != nil {
+		return 0, err
+	}
+
+	written, err := io.Copy(file, r)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	// The directory holds the new name; flush it so the rename survives a crash
+	if err == nil {
+		err = syncDir(dir)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return written, err
+	}
+	return written, nil
+}
+
+// Get implements Storage
+func (s *FileStorage) Get(name string) ([]byte, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+// GetRange implements Storage
+func (s *FileStorage) GetRange(name string, offset int64, p []byte) (int, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return 0, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	return file.ReadAt(p, offset)
+}
+
+// Delete implements Storage
+func (s *FileStorage) Delete(name string) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// List implements Storage
+func (s *FileStorage) List() ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	err := filepath.WalkDir(s.dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() || strings.HasSuffix(entry.Name(), tempSuffix) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			// The object was deleted while we were scanning
+			return nil
+		}
+		rel, err := filepath.Rel(s.dir, path)
+		if err != nil {
+			return err
+		}
+		objects = append(objects, ObjectInfo{Name: filepath.ToSlash(rel), Size: info.Size()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan data directory: %w", err)
+	}
+	return objects, nil
+}
+
+// Stat implements Storage
+func (s *FileStorage) Stat(name string) (ObjectInfo, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	if !info.Mode().IsRegular() {
+		return ObjectInfo{}, &fs.PathError{Op: "stat", Path: path, Err: fs.ErrNotExist}
+	}
+	return ObjectInfo{Name: name, Size: info.Size()}, nil
+}
+
+// Space returns the size of the file system holding the objects and the space
+// available on it
+func (s *FileStorage) Space() (total, free int64, err error) {
+	return diskSpace(s.dir)
+}
+
+// removeTempFiles deletes files left behind by writes a crash interrupted
+func (s *FileStorage) removeTempFiles() error {
+	var removed int
+	err := filepath.WalkDir(s.dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() || !strings.HasSuffix(entry.Name(), tempSuffix) {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		removed++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if removed > 0 {
+		log.Printf("Removed %d temporary files left by interrupted writes", removed)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package chunkserver
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"sort"
+	"sync"
+)
+
+// MemoryStorage keeps objects in memory. It lets chunkservers run without a
+// disk, for example in tests; everything is lost when the process exits.
+type MemoryStorage struct {
+	mu      sync.RWMutex
+	objects map[string][]byte
+}
+
+// NewMemoryStorage creates an empty in-memory storage
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{objects: make(map[string][]byte)}
+}
+
+// Put implements Storage
+func (s *MemoryStorage) Put(name string, r io.Reader) (int64, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return int64(len(data)), err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[name] = data
+	return int64(len(data)), nil
+}
+
+// Get implements Storage
+func (s *MemoryStorage) Get(name string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, exists := s.objects[name]
+	if !exists {
+		return nil, notExist("get", name)
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// GetRange implements Storage
+func (s *MemoryStorage) GetRange(name string, offset int64, p []byte) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, exists := s.objects[name]
+	if !exists {
+		return 0, notExist("get", name)
+	}
+	if offset < 0 {
+		return 0, &fs.PathError{Op: "get", Path: name, Err: errors.New("negative offset")}
+	}
+	if offset >= int64(len(data)) {
+		return 0, io.EOF
+	}
+
+	n := copy(p, data[offset:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Delete implements Storage
+func (s *MemoryStorage) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.objects[name]; !exists {
+		return notExist("delete", name)
+	}
+	delete(s.objects, name)
+	return nil
+}
+
+// List implements Storage
+func (s *MemoryStorage) List() ([]ObjectInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	objects := make([]ObjectInfo, 0, len(s.objects))
+	for name, data := range s.objects {
+		objects = append(objects, ObjectInfo{Name: name, Size: int64(len(data))})
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Name < objects[j].Name })
+	return objects, nil
+}
+
+// Stat implements Storage
+func (s *MemoryStorage) Stat(name string) (ObjectInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, exists := s.objects[name]
+	if !exists {
+		return ObjectInfo{}, notExist("stat", name)
+	}
+	return ObjectInfo{Name: name, Size: int64(len(data))}, nil
+}
+
+// notExist is the error for a missing object
+func notExist(op, name string) error {
+	return &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+}
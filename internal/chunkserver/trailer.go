@@ -0,0 +1,138 @@
+package chunkserver
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/sdudhani/godfs/pkg/gfs"
+)
+
+// Every chunk object ends in a trailer recording the chunk's version and block
+// checksums, so one rename installs the data together with what describes it:
+//
+//	block checksums  4 bytes each
+//	data size        8 bytes
+//	version          8 bytes
+//	trailer checksum 4 bytes, CRC-32C of the fields above
+//	magic            4 bytes, "GFSC"
+//
+// Numbers are big-endian. A chunk object without a trailer is corrupt.
+const (
+	trailerMagic = "GFSC"
+
+	// trailerFooterSize is the size of the trailer after the block checksums
+	trailerFooterSize = 8 + 8 + 4 + len(trailerMagic)
+)
+
+// chunkMeta describes a stored chunk
+type chunkMeta struct {
+	size    int64 // bytes of chunk data, without the trailer
+	version uint64
+	sums    []uint32 // CRC-32C of every checksumBlockSize block of data
+}
+
+// encodeTrailer returns the trailer of a chunk described by meta
+func encodeTrailer(meta chunkMeta) []byte {
+	trailer := make([]byte, 4*len(meta.sums)+trailerFooterSize)
+	for i, sum := range meta.sums {
+		binary.BigEndian.PutUint32(trailer[4*i:], sum)
+	}
+	footer := trailer[4*len(meta.sums):]
+	binary.BigEndian.PutUint64(footer, uint64(meta.size))
+	binary.BigEndian.PutUint64(footer[8:], meta.version)
+	binary.BigEndian.PutUint32(footer[16:], gfs.Checksum(trailer[:len(trailer)-8]))
+	copy(footer[20:], trailerMagic)
+	return trailer
+}
+
+// chunkObject yields the data read from r followed by the chunk's trailer
+type chunkObject struct {
+	data    io.Reader
+	sums    *blockChecksums
+	version uint64
+	trailer *bytes.Reader // nil until the data is exhausted
+}
+
+// Read implements io.Reader
+func (c *chunkObject) Read(p []byte) (int, error) {
+	if c.trailer == nil {
+		n, err := c.data.Read(p)
+		c.sums.Write(p[:n])
+		if err != io.EOF {
+			return n, err
+		}
+		c.trailer = bytes.NewReader(encodeTrailer(chunkMeta{size: c.sums.size, version: c.version, sums: c.sums.Sums()}))
+		if n > 0 {
+			return n, nil
+		}
+	}
+	return c.trailer.Read(p)
+}
+
+// chunkMeta returns the size, version and block checksums of a stored chunk
+func (s *Server) chunkMeta(chunkHandle string) (chunkMeta, error) {
+	info, err := s.Storage.Stat(chunkHandle)
+	if err != nil {
+		return chunkMeta{}, err
+	}
+
+	return s.readTrailer(chunkHandle, info.Size)
+}
+
+// readTrailer reads the trailer of a chunk object of objectSize bytes. An object
+// without a trailer, or whose trailer does not match its checksum, fails with
+// errCorruptChunk.
+func (s *Server) readTrailer(chunkHandle string, objectSize int64) (chunkMeta, error) {
+	if objectSize < int64(trailerFooterSize) {
+		return chunkMeta{}, fmt.Errorf("%w: chunk %s is too short to have a trailer", errCorruptChunk, chunkHandle)
+	}
+
+	footer := make([]byte, trailerFooterSize)
+	if err := s.readTrailerRange(chunkHandle, objectSize-int64(trailerFooterSize), footer); err != nil {
+		return chunkMeta{}, err
+	}
+	if string(footer[20:]) != trailerMagic {
+		return chunkMeta{}, fmt.Errorf("%w: chunk %s has no trailer", errCorruptChunk, chunkHandle)
+	}
+
+	size := int64(binary.BigEndian.Uint64(footer))
+	sumsSize := objectSize - int64(trailerFooterSize) - size
+	if size < 0 || sumsSize != 4*int64(blockCount(size)) {
+		return chunkMeta{}, fmt.Errorf("%w: trailer of chunk %s records %d bytes of data in a %d-byte object", errCorruptChunk, chunkHandle, size, objectSize)
+	}
+
+	trailer := make([]byte, sumsSize+int64(trailerFooterSize))
+	if err := s.readTrailerRange(chunkHandle, size, trailer); err != nil {
+		return chunkMeta{}, err
+	}
+	// The chunk may have been replaced since the footer was read
+	if !bytes.Equal(trailer[sumsSize:], footer) || gfs.Checksum(trailer[:len(trailer)-8]) != binary.BigEndian.Uint32(footer[16:]) {
+		return chunkMeta{}, fmt.Errorf("%w: trailer of chunk %s does not match its checksum", errCorruptChunk, chunkHandle)
+	}
+
+	meta := chunkMeta{
+		size:    size,
+		version: binary.BigEndian.Uint64(footer[8:]),
+		sums:    make([]uint32, blockCount(size)),
+	}
+	for i := range meta.sums {
+		meta.sums[i] = binary.BigEndian.Uint32(trailer[4*i:])
+	}
+	return meta, nil
+}
+
+// readTrailerRange fills p with the bytes of a chunk object at offset, which lie
+// in its trailer
+func (s *Server) readTrailerRange(chunkHandle string, offset int64, p []byte) error {
+	n, err := s.Storage.GetRange(chunkHandle, offset, p)
+	if n == len(p) {
+		return nil
+	}
+	if err == nil || err == io.EOF {
+		// The chunk shrank since it was looked at
+		err = io.ErrUnexpectedEOF
+	}
+	return fmt.Errorf("read trailer of chunk %s: %w", chunkHandle, err)
+}
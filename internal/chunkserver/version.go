@@ -0,0 +1,29 @@
+package chunkserver
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// chunkVersion returns the stored version of a chunk, or 0 if there is no such
+// chunk or it has no version
+func (s *Server) chunkVersion(chunkHandle string) (uint64, error) {
+	meta, err := s.chunkMeta(chunkHandle)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	return meta.version, err
+}
+
+// checkWriteVersion rejects writes that would replace a chunk with an older version
+func (s *Server) checkWriteVersion(chunkHandle string, version uint64) error {
+	current, err := s.chunkVersion(chunkHandle)
+	if err != nil {
+		return err
+	}
+	if version < current {
+		return fmt.Errorf("stale write of version %d, chunk %s is at version %d", version, chunkHandle, current)
+	}
+	return nil
+}
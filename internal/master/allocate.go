@@ -0,0 +1,255 @@
+package master
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/sdudhani/godfs/pkg/gfs"
+)
+
+// pendingChunk is a chunk handed out to a client that has not been committed yet
+type pendingChunk struct {
+	Filename    string
+	Index       int
+	Version     uint64
+	Locations   []string
+	AllocatedAt time.Time
+}
+
+// AllocateChunk assigns a handle and replicas for a chunk the client will write directly
+func (s *Server) AllocateChunk(ctx context.Context, req *gfs.AllocateChunkRequest) (*gfs.AllocateChunkResponse, error) {
+	index := int(req.GetChunkIndex())
+	if index < 0 {
+		return &gfs.AllocateChunkResponse{
+			Success: false,
+			Message: "Chunk index must not be negative",
+		}, nil
+	}
+
+	filename, err := normalizePath(req.GetFilename())
+	if err != nil {
+		return &gfs.AllocateChunkResponse{
+			Success: false,
+			Message: fmt.Sprintf("Invalid path: %v", err),
+		}, nil
+	}
+
+	// Get available chunkservers (needs to be called before acquiring lock to avoid deadlock)
+	availableChunkservers := s.getAvailableChunkservers()
+	if len(availableChunkservers) == 0 {
+		return &gfs.AllocateChunkResponse{
+			Success: false,
+			Message: "No chunkservers available",
+		}, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Fail before any data is written if the file could never be committed
+	if err := s.checkFilePath(filename); err != nil {
+		return &gfs.AllocateChunkResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	replicationFactor, err := s.replicationFactor(filename, req.GetReplicationFactor())
+	if err != nil {
+		return &gfs.AllocateChunkResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	// Replicate chunk to multiple chunkservers; the scheduler adds any replicas
+	// that do not fit on the chunkservers available now
+	locations := s.placeReplicas(nil, replicationFactor, time.Now().Unix())
+	if len(locations) == 0 {
+		return &gfs.AllocateChunkResponse{
+			Success: false,
+			Message: "No chunkserver has room for the chunk",
+		}, nil
+	}
+
+	// Allocating the same chunk again (e.g. a client retrying a failed write) is a
+	// new mutation of it: the handle is kept but its version is bumped, so replicas
+	// left behind by the earlier attempt become stale
+	chunkHandle, pending := s.findPendingChunk(filename, index)
+	if pending != nil {
+		pending.Version++
+		pending.Locations = locations
+		pending.AllocatedAt = time.Now()
+	} else {
+		chunkHandle, err = s.newChunkHandle()
+		if err != nil {
+			log.Printf("Failed to allocate chunk handle for %s: %v", filename, err)
+			return &gfs.AllocateChunkResponse{
+				Success: false,
+				Message: "Failed to allocate chunk handle",
+			}, nil
+		}
+		pending = &pendingChunk{
+			Filename:    filename,
+			Index:       index,
+			Version:     initialChunkVersion,
+			Locations:   locations,
+			AllocatedAt: time.Now(),
+		}
+		s.pendingChunks[chunkHandle] = pending
+	}
+
+	log.Printf("Allocated chunk %s version %d for %s on %v", chunkHandle, pending.Version, filename, locations)
+
+	return &gfs.AllocateChunkResponse{
+		Success:              true,
+		Message:              "Chunk allocated",
+		ChunkHandle:          chunkHandle,
+		ChunkserverAddresses: s.chunkserverAddresses(locations),
+		ChunkSize:            s.chunkSize,
+		Version:              pending.Version,
+	}, nil
+}
+
+// findPendingChunk returns the uncommitted allocation for chunk index of filename, if any;
+// callers must hold s.mu
+func (s *Server) findPendingChunk(filename string, index int) (string, *pendingChunk) {
+	for chunkHandle, pending := range s.pendingChunks {
+		if pending.Filename == filename && pending.Index == index {
+			return chunkHandle, pending
+		}
+	}
+	return "", nil
+}
+
+// CommitFile records a file whose chunks the client has written directly to chunkservers
+func (s *Server) CommitFile(ctx context.Context, req *gfs.CommitFileRequest) (*gfs.CommitFileResponse, error) {
+	filename, err := normalizePath(req.GetFilename())
+	if err != nil {
+		return &gfs.CommitFileResponse{
+			Success: false,
+			Message: fmt.Sprintf("Invalid path: %v", err),
+		}, nil
+	}
+	chunks := req.GetChunks()
+
+	if len(chunks) == 0 {
+		return &gfs.CommitFileResponse{
+			Success: false,
+			Message: "A file needs at least one chunk",
+		}, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// A directory may have taken the name while the data was written
+	if err := s.checkFilePath(filename); err != nil {
+		return &gfs.CommitFileResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	replicationFactor, err := s.replicationFactor(filename, req.GetReplicationFactor())
+	if err != nil {
+		return &gfs.CommitFileResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	// Every chunk must have been allocated for this file, at this position
+	chunkHandles := make([]string, 0, len(chunks))
+	chunkLocations := make(map[string][]string, len(chunks))
+	chunkVersions := make(map[string]uint64, len(chunks))
+	var size int64
+
+	for i, chunk := range chunks {
+		chunkHandle := chunk.GetChunkHandle()
+
+		pending, exists := s.pendingChunks[chunkHandle]
+		if !exists || pending.Filename != filename || pending.Index != i {
+			return &gfs.CommitFileResponse{
+				Success: false,
+				Message: fmt.Sprintf("Chunk %d (%s) was not allocated for %s", i, chunkHandle, filename),
+			}, nil
+		}
+
+		// A newer allocation of the same chunk supersedes this write
+		if chunk.GetVersion() != pending.Version {
+			return &gfs.CommitFileResponse{
+				Success: false,
+				Message: fmt.Sprintf("Chunk %d (%s) was written at version %d but is now at version %d",
+					i, chunkHandle, chunk.GetVersion(), pending.Version),
+			}, nil
+		}
+
+		// Reads find data by offset, so every chunk but the last must be full
+		if size := chunk.GetSize(); size < 0 || size > s.chunkSize || (i < len(chunks)-1 && size != s.chunkSize) {
+			return &gfs.CommitFileResponse{
+				Success: false,
+				Message: fmt.Sprintf("Chunk %d (%s) has %d bytes; chunks hold %d bytes and only the last may hold fewer",
+					i, chunkHandle, size, s.chunkSize),
+			}, nil
+		}
+
+		// Only replicas the master chose for this chunk are accepted
+		var locations []string
+		for _, id := range pending.Locations {
+			if info, exists := s.chunkservers[id]; exists && containsString(chunk.GetChunkserverAddresses(), info.Address) {
+				locations = append(locations, id)
+			}
+		}
+		if len(locations) == 0 {
+			return &gfs.CommitFileResponse{
+				Success: false,
+				Message: fmt.Sprintf("Chunk %d (%s) has no valid replicas", i, chunkHandle),
+			}, nil
+		}
+
+		chunkHandles = append(chunkHandles, chunkHandle)
+		chunkLocations[chunkHandle] = locations
+		chunkVersions[chunkHandle] = pending.Version
+		size += chunk.GetSize()
+	}
+
+	// Persist and apply the metadata and chunk locations
+	err = s.logAndApply(&logRecord{
+		Op:             opUploadFile,
+		Filename:       filename,
+		Metadata:       s.newFileMetadata(filename, chunkHandles, size, req.GetChecksum(), replicationFactor),
+		ChunkLocations: chunkLocations,
+		ChunkVersions:  chunkVersions,
+	})
+	if err != nil {
+		log.Printf("Failed to log commit of %s: %v", filename, err)
+		return &gfs.CommitFileResponse{
+			Success: false,
+			Message: "Failed to persist file metadata",
+		}, nil
+	}
+
+	for _, chunkHandle := range chunkHandles {
+		delete(s.pendingChunks, chunkHandle)
+	}
+
+	log.Printf("Committed file %s (%d bytes) as %d directly written chunks", filename, size, len(chunkHandles))
+
+	return &gfs.CommitFileResponse{
+		Success: true,
+		Message: fmt.Sprintf("File committed with %d chunks", len(chunkHandles)),
+	}, nil
+}
+
+// containsString reports whether list contains value
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package master
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sdudhani/godfs/pkg/gfs"
+)
+
+// allocateChunks registers a chunkserver and allocates chunks for filename,
+// returning the chunks as a client would commit them after writing size bytes
+// to each
+func allocateChunks(t *testing.T, s *Server, filename string, sizes ...int64) []*gfs.CommittedChunk {
+	t.Helper()
+	s.mu.Lock()
+	s.touchChunkserver("cs1", "cs1:9001", nil)
+	s.mu.Unlock()
+
+	var chunks []*gfs.CommittedChunk
+	for i, size := range sizes {
+		resp, err := s.AllocateChunk(context.Background(), &gfs.AllocateChunkRequest{Filename: filename, ChunkIndex: int32(i), ReplicationFactor: 1})
+		if err != nil || !resp.GetSuccess() {
+			t.Fatalf("AllocateChunk(%s, %d) = %s, %v", filename, i, resp.GetMessage(), err)
+		}
+		chunks = append(chunks, &gfs.CommittedChunk{
+			ChunkHandle:          resp.GetChunkHandle(),
+			Version:              resp.GetVersion(),
+			Size:                 size,
+			ChunkserverAddresses: resp.GetChunkserverAddresses(),
+		})
+	}
+	return chunks
+}
+
+func TestCommitFileChunkSizes(t *testing.T) {
+	tests := []struct {
+		name    string
+		sizes   []int64
+		wantErr bool
+	}{
+		{name: "one short chunk", sizes: []int64{3}},
+		{name: "empty file", sizes: []int64{0}},
+		{name: "full chunks and a short last one", sizes: []int64{10, 10, 4}},
+		{name: "full last chunk", sizes: []int64{10, 10}},
+		{name: "chunk over the chunk size", sizes: []int64{11}, wantErr: true},
+		{name: "short chunk before the last", sizes: []int64{10, 4, 10}, wantErr: true},
+		{name: "negative size", sizes: []int64{-1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, t.TempDir())
+			s.chunkSize = 10
+			chunks := allocateChunks(t, s, "/f", tt.sizes...)
+
+			resp, err := s.CommitFile(context.Background(), &gfs.CommitFileRequest{Filename: "/f", Chunks: chunks})
+			if err != nil {
+				t.Fatalf("CommitFile() error = %v", err)
+			}
+			if resp.GetSuccess() == tt.wantErr {
+				t.Fatalf("CommitFile() success = %t (%s), want %t", resp.GetSuccess(), resp.GetMessage(), !tt.wantErr)
+			}
+
+			fileMeta, committed := s.fileMetadata["/f"]
+			if committed != !tt.wantErr {
+				t.Fatalf("file committed = %t, want %t", committed, !tt.wantErr)
+			}
+			var want int64
+			for _, size := range tt.sizes {
+				want += size
+			}
+			if committed && fileMeta.Size != want {
+				t.Fatalf("file size = %d, want %d", fileMeta.Size, want)
+			}
+		})
+	}
+}
+
+func TestReallocationBumpsVersion(t *testing.T) {
+	tests := []struct {
+		name          string
+		allocations   int
+		commitVersion uint64 // 0 commits the latest version
+		wantErr       bool
+	}{
+		{name: "allocated once", allocations: 1},
+		{name: "allocated again", allocations: 2},
+		{name: "allocated three times", allocations: 3},
+		{name: "committed at an overtaken version", allocations: 2, commitVersion: initialChunkVersion, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, t.TempDir())
+			var chunks []*gfs.CommittedChunk
+			for range tt.allocations {
+				chunks = append(chunks, allocateChunks(t, s, "/f", 3)...)
+			}
+
+			// Every attempt at the same chunk keeps its handle with a higher version
+			for i, chunk := range chunks {
+				if chunk.GetChunkHandle() != chunks[0].GetChunkHandle() {
+					t.Fatalf("allocation %d got handle %s, want %s", i, chunk.GetChunkHandle(), chunks[0].GetChunkHandle())
+				}
+				if want := initialChunkVersion + uint64(i); chunk.GetVersion() != want {
+					t.Fatalf("allocation %d got version %d, want %d", i, chunk.GetVersion(), want)
+				}
+			}
+
+			chunk := chunks[len(chunks)-1]
+			if tt.commitVersion != 0 {
+				chunk.Version = tt.commitVersion
+			}
+			resp, err := s.CommitFile(context.Background(), &gfs.CommitFileRequest{Filename: "/f", Chunks: []*gfs.CommittedChunk{chunk}})
+			if err != nil || resp.GetSuccess() == tt.wantErr {
+				t.Fatalf("CommitFile() = %t (%s), %v; want success %t", resp.GetSuccess(), resp.GetMessage(), err, !tt.wantErr)
+			}
+			if !tt.wantErr && s.chunkVersions[chunk.GetChunkHandle()] != chunk.GetVersion() {
+				t.Fatalf("committed version = %d, want %d", s.chunkVersions[chunk.GetChunkHandle()], chunk.GetVersion())
+			}
+		})
+	}
+}
@@ -0,0 +1,314 @@
+package master
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/sdudhani/godfs/pkg/gfs"
+)
+
+const (
+	// DefaultBalancerThreshold is how far, as a fraction of the disk, a chunkserver
+	// may stray from the average utilization when Config.BalancerThreshold is unset
+	DefaultBalancerThreshold = 0.1
+
+	// DefaultBalancerBandwidth is the bytes per second the balancer may move when
+	// Config.BalancerBandwidth is unset
+	DefaultBalancerBandwidth = 32 << 20
+
+	// balancerInterval is how often the balancer looks for chunks to move
+	balancerInterval = 10 * time.Second
+
+	// maxConcurrentMoves caps balancer moves in flight across the cluster
+	maxConcurrentMoves = 4
+)
+
+// chunkMove is a replica being moved from one chunkserver to another
+type chunkMove struct {
+	ChunkHandle string
+	Version     uint64
+	Size        int64
+	SourceID    string
+	TargetID    string
+}
+
+// balancerState is the balancer's bookkeeping; it is guarded by s.mu
+type balancerState struct {
+	running   bool
+	threshold float64
+	bandwidth int64 // bytes per second
+
+	// budget is the bytes that may still be moved. It refills at bandwidth, holds
+	// at most one interval's worth and goes negative after a large chunk.
+	budget     int64
+	lastRefill time.Time
+
+	moves       map[string]*chunkMove // chunk handle -> move in flight
+	skew        float64               // as of the last check
+	movedChunks int64
+	movedBytes  int64
+}
+
+// newBalancerState creates the balancer's state from the master's configuration
+func newBalancerState(cfg Config) balancerState {
+	return balancerState{
+		running:    cfg.StartBalancer,
+		threshold:  cfg.BalancerThreshold,
+		bandwidth:  cfg.BalancerBandwidth,
+		lastRefill: time.Now(),
+		moves:      make(map[string]*chunkMove),
+	}
+}
+
+// SetBalancer starts or stops the balancer. Stopping lets moves in progress finish.
+func (s *Server) SetBalancer(ctx context.Context, req *gfs.SetBalancerRequest) (*gfs.SetBalancerResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := &s.balancer
+	if b.running != req.GetRunning() {
+		b.running = req.GetRunning()
+		b.budget = 0
+		b.lastRefill = time.Now()
+		log.Printf("Balancer running: %t", b.running)
+	}
+
+	message := "Balancer started"
+	if !b.running {
+		message = "Balancer stopped"
+		if len(b.moves) > 0 {
+			message += fmt.Sprintf("; %d moves in progress will finish", len(b.moves))
+		}
+	}
+	return &gfs.SetBalancerResponse{
+		Success: true,
+		Message: message,
+		Status:  s.balancerStatus(),
+	}, nil
+}
+
+// balancePeriodically checks the utilization skew and starts chunk moves while
+// the balancer is running
+func (s *Server) balancePeriodically() {
+	ticker := time.NewTicker(balancerInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.balance()
+	}
+}
+
+// balance moves chunks from chunkservers above the average utilization to ones
+// below it, within the bandwidth budget and the copy limits
+func (s *Server) balance() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := &s.balancer
+	now := time.Now()
+
+	sources, targets, skew := s.utilizationSpread(now.Unix())
+	b.skew = skew
+
+	if b.running {
+		refill := int64(now.Sub(b.lastRefill).Seconds() * float64(b.bandwidth))
+		b.budget = min(b.budget+refill, int64(balancerInterval.Seconds())*b.bandwidth)
+	}
+	b.lastRefill = now
+
+	// Until chunkservers had time to report, locations cannot be trusted
+	if !b.running || !s.locationsPruned {
+		return
+	}
+
+	for len(b.moves) < maxConcurrentMoves && b.budget > 0 && len(sources) > 0 && len(targets) > 0 {
+		move := s.planMove(sources[0], targets, now.Unix())
+		if move == nil {
+			sources = sources[1:]
+			continue
+		}
+		// Take turns among the sources
+		sources = append(sources[1:], sources[0])
+
+		// Moves count as copies, so re-replication and trimming leave the chunk alone
+		started := s.startCopy(&chunkCopy{
+			ChunkHandle: move.ChunkHandle,
+			Version:     move.Version,
+			SourceID:    move.SourceID,
+			TargetID:    move.TargetID,
+			Move:        move,
+		}, now)
+		if !started {
+			continue
+		}
+		b.moves[move.ChunkHandle] = move
+		b.budget -= move.Size
+
+		log.Printf("Moving chunk %s (%d bytes) from %s to %s", move.ChunkHandle, move.Size, move.SourceID, move.TargetID)
+	}
+}
+
+// utilizationSpread returns the chunkservers to move chunks from, fullest first,
+// and to move chunks to, emptiest first, along with the largest distance from the
+// average utilization. Only available chunkservers that report their disk size
+// count. Nothing moves unless a chunkserver strays from the average by more than
+// the threshold; the other side then takes any chunkserver past the average.
+// Callers must hold s.mu.
+func (s *Server) utilizationSpread(now int64) ([]string, []string, float64) {
+	utilization := make(map[string]float64)
+	var sum float64
+	for id, info := range s.chunkservers {
+		if info.available(now) && info.Stats.GetTotalBytes() > 0 && !s.draining[id] {
+			utilization[id] = info.utilization()
+			sum += utilization[id]
+		}
+	}
+	if len(utilization) < 2 {
+		return nil, nil, 0
+	}
+	average := sum / float64(len(utilization))
+
+	threshold := s.balancer.threshold
+	var over, aboveAverage, under, belowAverage []string
+	var skew float64
+	for id, u := range utilization {
+		skew = math.Max(skew, math.Abs(u-average))
+		switch {
+		case u > average+threshold:
+			over = append(over, id)
+		case u > average:
+			aboveAverage = append(aboveAverage, id)
+		case u < average-threshold:
+			under = append(under, id)
+		case u < average:
+			belowAverage = append(belowAverage, id)
+		}
+	}
+
+	switch {
+	case len(over) == 0 && len(under) == 0:
+		return nil, nil, skew
+	case len(over) == 0:
+		over = aboveAverage
+	case len(under) == 0:
+		under = belowAverage
+	}
+
+	sort.Slice(over, func(i, j int) bool { return utilization[over[i]] > utilization[over[j]] })
+	sort.Slice(under, func(i, j int) bool { return utilization[under[i]] < utilization[under[j]] })
+	return over, under, skew
+}
+
+// planMove picks a chunk on sourceID to move to one of targets. Only chunks of
+// live files with all their replicas, a known size and no copy in flight move,
+// and never into a more crowded failure domain than the one they leave. The
+// target is charged the chunk by the placement policy. Callers must hold s.mu.
+func (s *Server) planMove(sourceID string, targets []string, now int64) *chunkMove {
+	r := &s.replication
+	source := s.chunkservers[sourceID]
+	if r.busy[sourceID] >= maxClonesPerChunkserver {
+		return nil
+	}
+
+	for _, fileMeta := range s.fileMetadata {
+		goal := fileMeta.replicationGoal()
+
+		for _, chunkHandle := range fileMeta.ChunkHandles {
+			if _, moving := s.balancer.moves[chunkHandle]; moving || len(r.cloning[chunkHandle]) > 0 || r.tasks[chunkHandle] != nil {
+				continue
+			}
+			size, known := s.chunkSizes[chunkHandle]
+			if !known || size <= 0 || source.deletionPending(chunkHandle) {
+				continue
+			}
+			lasting := s.lastingReplicas(chunkHandle, now)
+			if !containsString(lasting, sourceID) || len(lasting) < goal {
+				continue
+			}
+
+			// The other replicas stay where they are
+			rest := s.placementNodes(removeString(lasting, sourceID))
+			sourceShared := s.placementNodes([]string{sourceID})[0].sharedDomains(rest)
+
+			var candidates []string
+			for _, id := range targets {
+				info := s.chunkservers[id]
+				if containsString(s.chunkLocations[chunkHandle], id) || info.deletionPending(chunkHandle) ||
+					r.busy[id] >= maxClonesPerChunkserver {
+					continue
+				}
+				if compareShared(s.placementNodes([]string{id})[0].sharedDomains(rest), sourceShared) <= 0 {
+					candidates = append(candidates, id)
+				}
+			}
+
+			picked := s.placeOn(candidates, removeString(lasting, sourceID), 1)
+			if len(picked) == 0 {
+				continue
+			}
+			return &chunkMove{
+				ChunkHandle: chunkHandle,
+				Version:     s.chunkVersions[chunkHandle],
+				Size:        size,
+				SourceID:    sourceID,
+				TargetID:    picked[0],
+			}
+		}
+	}
+	return nil
+}
+
+// finishMove completes a move once its copy finished with size bytes or failed.
+// A copy with the size chunkservers reported for the chunk replaces the replica
+// on the old chunkserver, which is told to delete it. Callers must hold s.mu.
+func (s *Server) finishMove(move *chunkMove, size int64, err error) {
+	delete(s.balancer.moves, move.ChunkHandle)
+	if err == nil && size != move.Size {
+		err = fmt.Errorf("copied %d bytes, expected %d", size, move.Size)
+	}
+
+	if err != nil {
+		log.Printf("Failed to move chunk %s from %s to %s: %v", move.ChunkHandle, move.SourceID, move.TargetID, err)
+		// A bad copy must not be adopted when the target next reports
+		if size > 0 {
+			s.queueChunkDeletion(move.TargetID, move.ChunkHandle)
+		}
+		return
+	}
+
+	// A chunk deleted or rewritten during the move keeps its old replica
+	s.addReplica(move.ChunkHandle, move.Version, move.TargetID)
+	if !containsString(s.chunkLocations[move.ChunkHandle], move.TargetID) {
+		return
+	}
+
+	err = s.logAndApply(&logRecord{
+		Op:             opSetLocations,
+		ChunkLocations: map[string][]string{move.ChunkHandle: removeString(s.chunkLocations[move.ChunkHandle], move.SourceID)},
+	})
+	if err != nil {
+		log.Printf("Failed to log new locations of chunk %s: %v", move.ChunkHandle, err)
+		return
+	}
+	s.queueChunkDeletion(move.SourceID, move.ChunkHandle)
+
+	s.balancer.movedChunks++
+	s.balancer.movedBytes += size
+	log.Printf("Moved chunk %s from %s to %s", move.ChunkHandle, move.SourceID, move.TargetID)
+}
+
+// balancerStatus summarizes the balancer; callers must hold s.mu
+func (s *Server) balancerStatus() *gfs.BalancerStatus {
+	b := &s.balancer
+	return &gfs.BalancerStatus{
+		Running:     b.running,
+		Skew:        b.skew,
+		ActiveMoves: int32(len(b.moves)),
+		MovedChunks: b.movedChunks,
+		MovedBytes:  b.movedBytes,
+	}
+}
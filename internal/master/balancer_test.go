@@ -0,0 +1,111 @@
+package master
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sdudhani/godfs/pkg/gfs"
+)
+
+// newBalancerServer builds a master with chunk c1 of 10 bytes on cs1 and cs2 and
+// an empty cs3, each chunkserver in a rack of its own
+func newBalancerServer(t *testing.T) *Server {
+	t.Helper()
+	s := newTestServer(t, t.TempDir())
+	for i, id := range []string{"cs1", "cs2", "cs3"} {
+		s.chunkservers[id] = &ChunkserverInfo{
+			Address:   id + ":9000",
+			Topology:  &gfs.Topology{Zone: "z", Rack: []string{"r1", "r2", "r3"}[i], Host: id},
+			IsHealthy: true,
+			LastSeen:  time.Now().Unix(),
+			InFlight:  make(map[uint64]*issuedCommand),
+		}
+	}
+	s.fileMetadata["/f"] = &FileMetadata{ChunkHandles: []string{"c1"}, ReplicationFactor: 2}
+	s.chunkLocations["c1"] = []string{"cs1", "cs2"}
+	s.chunkVersions["c1"] = 1
+	s.chunkSizes["c1"] = 10
+	return s
+}
+
+func TestPlanMove(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(s *Server)
+		want    *chunkMove
+	}{
+		{
+			name: "movable",
+			want: &chunkMove{ChunkHandle: "c1", Version: 1, Size: 10, SourceID: "cs1", TargetID: "cs3"},
+		},
+		{name: "size unknown", prepare: func(s *Server) { delete(s.chunkSizes, "c1") }},
+		{name: "missing replicas", prepare: func(s *Server) { s.fileMetadata["/f"].ReplicationFactor = 3 }},
+		{name: "queued for re-replication", prepare: func(s *Server) { s.replication.tasks["c1"] = &replicationTask{ChunkHandle: "c1"} }},
+		{name: "copy in flight", prepare: func(s *Server) { s.replication.cloning["c1"] = []string{"cs3"} }},
+		{name: "already moving", prepare: func(s *Server) { s.balancer.moves["c1"] = &chunkMove{ChunkHandle: "c1"} }},
+		{name: "source busy", prepare: func(s *Server) { s.replication.busy["cs1"] = maxClonesPerChunkserver }},
+		{name: "target busy", prepare: func(s *Server) { s.replication.busy["cs3"] = maxClonesPerChunkserver }},
+		{name: "source deleting it", prepare: func(s *Server) { s.queueChunkDeletion("cs1", "c1") }},
+		{name: "target deleting it", prepare: func(s *Server) { s.queueChunkDeletion("cs3", "c1") }},
+		{name: "into the rack of another replica", prepare: func(s *Server) { s.chunkservers["cs3"].Topology.Rack = "r2" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newBalancerServer(t)
+			if tt.prepare != nil {
+				tt.prepare(s)
+			}
+
+			if got := s.planMove("cs1", []string{"cs3"}, time.Now().Unix()); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("planMove() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFinishMove(t *testing.T) {
+	tests := []struct {
+		name          string
+		size          int64
+		err           error
+		rewritten     bool // the chunk got a new version during the move
+		wantLocations []string
+		wantDeleted   string // chunkserver told to delete its replica
+	}{
+		{name: "copied", size: 10, wantLocations: []string{"cs2", "cs3"}, wantDeleted: "cs1"},
+		{name: "copy of the wrong size", size: 4, wantLocations: []string{"cs1", "cs2"}, wantDeleted: "cs3"},
+		{name: "copy failed", err: errors.New("source unreachable"), wantLocations: []string{"cs1", "cs2"}},
+		{name: "rewritten during the move", size: 10, rewritten: true, wantLocations: []string{"cs1", "cs2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newBalancerServer(t)
+			move := &chunkMove{ChunkHandle: "c1", Version: 1, Size: 10, SourceID: "cs1", TargetID: "cs3"}
+			s.balancer.moves["c1"] = move
+			if tt.rewritten {
+				s.chunkVersions["c1"] = 2
+			}
+
+			s.finishMove(move, tt.size, tt.err)
+
+			if len(s.balancer.moves) != 0 {
+				t.Fatalf("move still in flight: %v", s.balancer.moves)
+			}
+			if got := s.chunkLocations["c1"]; !reflect.DeepEqual(got, tt.wantLocations) {
+				t.Fatalf("locations = %v, want %v", got, tt.wantLocations)
+			}
+			for _, id := range []string{"cs1", "cs2", "cs3"} {
+				if pending := s.chunkservers[id].deletionPending("c1"); pending != (id == tt.wantDeleted) {
+					t.Fatalf("%s deleting c1 = %t, want %t", id, pending, id == tt.wantDeleted)
+				}
+			}
+			if moved := s.balancer.movedChunks == 1; moved != (tt.wantDeleted == "cs1") {
+				t.Fatalf("moved chunks = %d", s.balancer.movedChunks)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package master
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/sdudhani/godfs/pkg/gfs"
+)
+
+// reportGracePeriod is how long after startup the master waits for chunk
+// reports before dropping recovered locations that no chunkserver confirmed
+const reportGracePeriod = 2 * time.Minute
+
+// touchChunkserver registers a chunkserver or refreshes its liveness, address and
+// topology; callers must hold s.mu
+func (s *Server) touchChunkserver(chunkserverID, address string, topology *gfs.Topology) *ChunkserverInfo {
+	// Chunkservers that predate stable IDs identify themselves by address
+	if address == "" {
+		address = chunkserverID
+	}
+
+	info, exists := s.chunkservers[chunkserverID]
+	if !exists {
+		info = &ChunkserverInfo{Address: address, InFlight: make(map[uint64]*issuedCommand)}
+		s.chunkservers[chunkserverID] = info
+		log.Printf("Registered chunkserver %s at %s", chunkserverID, address)
+	} else if info.Address != address {
+		log.Printf("Chunkserver %s moved from %s to %s", chunkserverID, info.Address, address)
+		s.closeChunkserverConn(info.Address)
+		info.Address = address
+	}
+
+	// Chunkservers that predate topology labels keep whatever was known
+	if topology != nil {
+		info.Topology = topology
+	}
+
+	// A chunkserver coming back from failure lost its locations and must report again
+	if !info.IsHealthy {
+		info.LastReport = 0
+	}
+
+	info.LastSeen = time.Now().Unix()
+	info.IsHealthy = true
+	return info
+}
+
+// ReportChunks rebuilds the locations held by one chunkserver from its full chunk list.
+// The chunkserver is the source of truth for what it stores, so these updates are not
+// written to the operation log; logged locations only serve as hints after a restart.
+func (s *Server) ReportChunks(ctx context.Context, req *gfs.ReportChunksRequest) (*gfs.ReportChunksResponse, error) {
+	chunkserverID := req.GetChunkserverId()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info := s.touchChunkserver(chunkserverID, req.GetAddress(), req.GetTopology())
+
+	reported := make(map[string]bool, len(req.GetChunks()))
+	newer := make(map[string]uint64)
+	var added, stale, unknown int
+
+	for _, chunk := range req.GetChunks() {
+		chunkHandle := chunk.GetChunkHandle()
+
+		locations, known := s.chunkLocations[chunkHandle]
+		if !known {
+			// Chunks being written are not committed yet; anything else is an
+			// orphan of a reclaimed file or an abandoned upload
+			if _, pending := s.pendingChunks[chunkHandle]; !pending {
+				s.queueChunkDeletion(chunkserverID, chunkHandle)
+				unknown++
+			}
+			continue
+		}
+
+		// A replica that is being deleted, e.g. a surplus one, no longer counts
+		if info.deletionPending(chunkHandle) {
+			continue
+		}
+
+		// A replica that missed a mutation is stale: it is never served and is
+		// deleted. A higher version means the master failed after a chunkserver
+		// accepted a mutation, so the master adopts it.
+		version := s.chunkVersions[chunkHandle]
+		switch {
+		case chunk.GetVersion() < version:
+			log.Printf("Chunkserver %s holds stale chunk %s (version %d, current %d)",
+				chunkserverID, chunkHandle, chunk.GetVersion(), version)
+			s.queueChunkDeletion(chunkserverID, chunkHandle)
+			stale++
+			continue
+		case chunk.GetVersion() > version:
+			log.Printf("Chunkserver %s holds chunk %s at version %d, newer than %d; adopting it",
+				chunkserverID, chunkHandle, chunk.GetVersion(), version)
+			newer[chunkHandle] = chunk.GetVersion()
+		}
+		reported[chunkHandle] = true
+		s.chunkSizes[chunkHandle] = chunk.GetSize()
+
+		if !containsString(locations, chunkserverID) {
+			s.chunkLocations[chunkHandle] = append(locations, chunkserverID)
+			added++
+		}
+	}
+
+	// Drop locations this chunkserver no longer backs. A chunk committed while the
+	// report was in flight may be dropped too; the next report restores it.
+	var removed int
+	for chunkHandle, locations := range s.chunkLocations {
+		if !reported[chunkHandle] && containsString(locations, chunkserverID) {
+			s.chunkLocations[chunkHandle] = removeString(locations, chunkserverID)
+			removed++
+		}
+	}
+
+	// Replicas that were current before the adopted versions are stale now;
+	// they drop out when their chunkservers next report
+	if len(newer) > 0 {
+		if err := s.logAndApply(&logRecord{Op: opSetVersions, ChunkVersions: newer}); err != nil {
+			log.Printf("Failed to record chunk versions reported by %s: %v", chunkserverID, err)
+		}
+	}
+
+	info.LastReport = time.Now().Unix()
+
+	log.Printf("Chunk report from %s: %d chunks, %d locations added, %d removed, %d stale, %d unknown chunks",
+		chunkserverID, len(req.GetChunks()), added, removed, stale, unknown)
+
+	return &gfs.ReportChunksResponse{
+		Success: true,
+		Message: "Chunk report received",
+	}, nil
+}
+
+// dropCorruptReplicas forgets replicas that a chunkserver found corrupt and has
+// them deleted, so the chunks are re-replicated from intact replicas. The last
+// replica of a chunk stays on disk for manual recovery. Callers must hold s.mu.
+func (s *Server) dropCorruptReplicas(chunkserverID string, chunkHandles []string) {
+	if len(chunkHandles) == 0 {
+		return
+	}
+
+	now := time.Now().Unix()
+	for _, chunkHandle := range chunkHandles {
+		locations := s.chunkLocations[chunkHandle]
+		if !containsString(locations, chunkserverID) {
+			continue
+		}
+
+		log.Printf("Chunkserver %s holds a corrupt replica of chunk %s", chunkserverID, chunkHandle)
+		s.chunkLocations[chunkHandle] = removeString(locations, chunkserverID)
+
+		if len(s.liveReplicas(chunkHandle, now)) == 0 {
+			log.Printf("Chunk %s has no intact replica left; keeping the corrupt one on %s", chunkHandle, chunkserverID)
+			continue
+		}
+		s.queueChunkDeletion(chunkserverID, chunkHandle)
+	}
+
+	// Start copying intact replicas right away
+	s.checkReplication()
+}
+
+// pruneUnconfirmedLocations drops recovered locations on chunkservers that have not
+// reported since the master started; callers must hold s.mu
+func (s *Server) pruneUnconfirmedLocations() {
+	var pruned int
+
+	for chunkHandle, locations := range s.chunkLocations {
+		var confirmed []string
+		for _, addr := range locations {
+			if info, exists := s.chunkservers[addr]; exists && info.LastReport > 0 {
+				confirmed = append(confirmed, addr)
+			}
+		}
+
+		if len(confirmed) != len(locations) {
+			pruned += len(locations) - len(confirmed)
+			s.chunkLocations[chunkHandle] = confirmed
+		}
+	}
+
+	log.Printf("Pruned %d chunk locations not confirmed by any chunk report", pruned)
+}
+
+// removeString returns a copy of list without value
+func removeString(list []string, value string) []string {
+	var result []string
+	for _, item := range list {
+		if item != value {
+			result = append(result, item)
+		}
+	}
+	return result
+}
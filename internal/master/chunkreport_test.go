@@ -0,0 +1,82 @@
+package master
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/sdudhani/godfs/pkg/gfs"
+)
+
+func TestReportChunks(t *testing.T) {
+	tests := []struct {
+		name          string
+		reported      []*gfs.ChunkReport
+		wantLocations map[string][]string
+		wantVersion   uint64 // of c1
+		wantDeleted   []string
+	}{
+		{
+			name:          "current replica",
+			reported:      []*gfs.ChunkReport{{ChunkHandle: "c1", Version: 2}},
+			wantLocations: map[string][]string{"c1": {"cs2", "cs1"}, "c2": {"cs2"}},
+			wantVersion:   2,
+		},
+		{
+			name:          "stale replica",
+			reported:      []*gfs.ChunkReport{{ChunkHandle: "c1", Version: 1}},
+			wantLocations: map[string][]string{"c1": {"cs2"}, "c2": {"cs2"}},
+			wantVersion:   2,
+			wantDeleted:   []string{"c1"},
+		},
+		{
+			name:          "newer replica",
+			reported:      []*gfs.ChunkReport{{ChunkHandle: "c1", Version: 3}},
+			wantLocations: map[string][]string{"c1": {"cs2", "cs1"}, "c2": {"cs2"}},
+			wantVersion:   3,
+		},
+		{
+			name:          "orphan of a reclaimed file",
+			reported:      []*gfs.ChunkReport{{ChunkHandle: "c9", Version: 1}},
+			wantLocations: map[string][]string{"c1": {"cs2"}, "c2": {"cs2"}},
+			wantVersion:   2,
+			wantDeleted:   []string{"c9"},
+		},
+		{
+			name:          "chunk being written",
+			reported:      []*gfs.ChunkReport{{ChunkHandle: "pending", Version: 1}},
+			wantLocations: map[string][]string{"c1": {"cs2"}, "c2": {"cs2"}},
+			wantVersion:   2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, t.TempDir())
+			s.chunkVersions = map[string]uint64{"c1": 2, "c2": 1}
+			// cs1 was believed to hold c2 but does not report it
+			s.chunkLocations = map[string][]string{"c1": {"cs2"}, "c2": {"cs1", "cs2"}}
+			s.pendingChunks["pending"] = &pendingChunk{Filename: "/f", Version: 1}
+
+			resp, err := s.ReportChunks(context.Background(), &gfs.ReportChunksRequest{ChunkserverId: "cs1", Address: "cs1:9001", Chunks: tt.reported})
+			if err != nil || !resp.GetSuccess() {
+				t.Fatalf("ReportChunks() = %s, %v", resp.GetMessage(), err)
+			}
+
+			if !reflect.DeepEqual(s.chunkLocations, tt.wantLocations) {
+				t.Fatalf("locations = %v, want %v", s.chunkLocations, tt.wantLocations)
+			}
+			if got := s.chunkVersions["c1"]; got != tt.wantVersion {
+				t.Fatalf("version of c1 = %d, want %d", got, tt.wantVersion)
+			}
+
+			var deleted []string
+			if cmd := s.chunkservers["cs1"].queuedCommand(gfs.ChunkserverCommand_DELETE); cmd != nil {
+				deleted = cmd.GetChunkHandles()
+			}
+			if !reflect.DeepEqual(deleted, tt.wantDeleted) {
+				t.Fatalf("deletions queued for cs1 = %v, want %v", deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
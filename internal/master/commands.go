@@ -0,0 +1,192 @@
+package master
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/sdudhani/godfs/pkg/gfs"
+)
+
+// commandTimeout is how long the master waits for a chunkserver to acknowledge a
+// command before forgetting it
+const commandTimeout = 10 * time.Minute
+
+// issuedCommand is a command sent to a chunkserver that has not been acknowledged yet
+type issuedCommand struct {
+	Command  *gfs.ChunkserverCommand
+	IssuedAt time.Time
+}
+
+// queueCommand schedules a command for a chunkserver's next heartbeat; callers must hold s.mu
+func (s *Server) queueCommand(chunkserverID string, cmd *gfs.ChunkserverCommand) bool {
+	info, exists := s.chunkservers[chunkserverID]
+	if !exists {
+		return false
+	}
+
+	s.nextCommandID++
+	cmd.Id = s.nextCommandID
+	info.Commands = append(info.Commands, cmd)
+	return true
+}
+
+// queuedCommand returns the first queued command of the given type, or nil; callers
+// must hold s.mu
+func (info *ChunkserverInfo) queuedCommand(commandType gfs.ChunkserverCommand_Type) *gfs.ChunkserverCommand {
+	for _, cmd := range info.Commands {
+		if cmd.GetType() == commandType {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// awaitingCommand reports whether a command of the given type is queued or not yet
+// acknowledged; callers must hold s.mu
+func (info *ChunkserverInfo) awaitingCommand(commandType gfs.ChunkserverCommand_Type) bool {
+	if info.queuedCommand(commandType) != nil {
+		return true
+	}
+	for _, issued := range info.InFlight {
+		if issued.Command.GetType() == commandType {
+			return true
+		}
+	}
+	return false
+}
+
+// deletionPending reports whether the chunkserver was told, or is about to be told,
+// to delete a chunk; callers must hold s.mu
+func (info *ChunkserverInfo) deletionPending(chunkHandle string) bool {
+	if cmd := info.queuedCommand(gfs.ChunkserverCommand_DELETE); cmd != nil && containsString(cmd.GetChunkHandles(), chunkHandle) {
+		return true
+	}
+	for _, issued := range info.InFlight {
+		if issued.Command.GetType() == gfs.ChunkserverCommand_DELETE && containsString(issued.Command.GetChunkHandles(), chunkHandle) {
+			return true
+		}
+	}
+	return false
+}
+
+// takeCommands hands over the queued commands of a chunkserver and remembers them
+// until they are acknowledged; callers must hold s.mu
+func (s *Server) takeCommands(info *ChunkserverInfo, now time.Time) []*gfs.ChunkserverCommand {
+	commands := info.Commands
+	info.Commands = nil
+
+	for _, cmd := range commands {
+		info.InFlight[cmd.GetId()] = &issuedCommand{Command: cmd, IssuedAt: now}
+	}
+	return commands
+}
+
+// handleCommandResults applies the acknowledgements sent with a heartbeat and forgets
+// commands that were never acknowledged; callers must hold s.mu
+func (s *Server) handleCommandResults(chunkserverID string, info *ChunkserverInfo, results []*gfs.CommandResult, now time.Time) {
+	for _, result := range results {
+		issued, exists := info.InFlight[result.GetCommandId()]
+		if !exists {
+			continue
+		}
+		delete(info.InFlight, result.GetCommandId())
+
+		cmd := issued.Command
+		if cmd.GetType() == gfs.ChunkserverCommand_REPLICATE {
+			// Failed copies are retried, so the copy bookkeeping sees every outcome
+			var err error
+			if !result.GetSuccess() {
+				err = errors.New(result.GetMessage())
+			}
+			s.finishCopy(cmd.GetId(), result.GetSize(), err)
+			continue
+		}
+		if !result.GetSuccess() {
+			log.Printf("Chunkserver %s failed command %d (%s): %s", chunkserverID, cmd.GetId(), cmd.GetType(), result.GetMessage())
+			continue
+		}
+
+		if cmd.GetType() == gfs.ChunkserverCommand_DRAIN {
+			log.Printf("Chunkserver %s set drain mode to %t", chunkserverID, cmd.GetDrain())
+		}
+	}
+
+	// A lost delete is found again in the next chunk report, a lost report is
+	// requested again and a lost copy is given up by expireCopies, so dropping
+	// stale commands is safe
+	for id, issued := range info.InFlight {
+		if now.Sub(issued.IssuedAt) > commandTimeout {
+			log.Printf("Chunkserver %s never acknowledged command %d (%s)", chunkserverID, id, issued.Command.GetType())
+			delete(info.InFlight, id)
+		}
+	}
+}
+
+// forgetCommand drops a command that is queued for a chunkserver or awaiting its
+// acknowledgement; callers must hold s.mu
+func (s *Server) forgetCommand(chunkserverID string, commandID uint64) {
+	info, exists := s.chunkservers[chunkserverID]
+	if !exists {
+		return
+	}
+
+	delete(info.InFlight, commandID)
+	for i, cmd := range info.Commands {
+		if cmd.GetId() == commandID {
+			info.Commands = append(info.Commands[:i:i], info.Commands[i+1:]...)
+			return
+		}
+	}
+}
+
+// addReplica records a replica copied to a chunkserver, unless the chunk was
+// deleted or rewritten while it was being copied; callers must hold s.mu
+func (s *Server) addReplica(chunkHandle string, version uint64, chunkserverID string) {
+	current, exists := s.chunkVersions[chunkHandle]
+	if !exists {
+		s.queueChunkDeletion(chunkserverID, chunkHandle)
+		return
+	}
+	// The next chunk report sorts out a copy that was overtaken by a newer version
+	if current != version {
+		return
+	}
+
+	locations := s.chunkLocations[chunkHandle]
+	if containsString(locations, chunkserverID) {
+		return
+	}
+
+	locations = append(append([]string(nil), locations...), chunkserverID)
+	if err := s.logAndApply(&logRecord{
+		Op:             opSetLocations,
+		ChunkLocations: map[string][]string{chunkHandle: locations},
+	}); err != nil {
+		log.Printf("Failed to log new locations of chunk %s: %v", chunkHandle, err)
+		return
+	}
+	log.Printf("Replicated chunk %s to %s", chunkHandle, chunkserverID)
+}
+
+// queueChunkDeletion schedules a chunk for deletion on a chunkserver with its next
+// heartbeat; callers must hold s.mu
+func (s *Server) queueChunkDeletion(chunkserverID, chunkHandle string) {
+	info, exists := s.chunkservers[chunkserverID]
+	if !exists {
+		return
+	}
+
+	// Batch deletions into a single queued command
+	if cmd := info.queuedCommand(gfs.ChunkserverCommand_DELETE); cmd != nil {
+		if !containsString(cmd.ChunkHandles, chunkHandle) {
+			cmd.ChunkHandles = append(cmd.ChunkHandles, chunkHandle)
+		}
+		return
+	}
+
+	s.queueCommand(chunkserverID, &gfs.ChunkserverCommand{
+		Type:         gfs.ChunkserverCommand_DELETE,
+		ChunkHandles: []string{chunkHandle},
+	})
+}
@@ -0,0 +1,79 @@
+package master
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sdudhani/godfs/pkg/gfs"
+)
+
+func TestReplicateCommand(t *testing.T) {
+	tests := []struct {
+		name          string
+		result        *gfs.CommandResult // nil if the target never answers
+		wantLocations []string
+	}{
+		{
+			name:          "acknowledged",
+			result:        &gfs.CommandResult{Success: true, Size: 10},
+			wantLocations: []string{"cs1", "cs2"},
+		},
+		{
+			name:          "failed",
+			result:        &gfs.CommandResult{Message: "source unreachable"},
+			wantLocations: []string{"cs1"},
+		},
+		{
+			name:          "never acknowledged",
+			wantLocations: []string{"cs1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, t.TempDir())
+			for _, id := range []string{"cs1", "cs2"} {
+				s.chunkservers[id] = &ChunkserverInfo{Address: id + ":9000", InFlight: make(map[uint64]*issuedCommand)}
+			}
+			s.chunkLocations["c1"] = []string{"cs1"}
+			s.chunkVersions["c1"] = 1
+
+			now := time.Now()
+			if !s.startCopy(&chunkCopy{ChunkHandle: "c1", Version: 1, Goal: 2, SourceID: "cs1", TargetID: "cs2"}, now) {
+				t.Fatal("startCopy() = false")
+			}
+			s.replication.active++
+
+			target := s.chunkservers["cs2"]
+			commands := s.takeCommands(target, now)
+			if len(commands) != 1 || commands[0].GetType() != gfs.ChunkserverCommand_REPLICATE || commands[0].GetSourceAddress() != "cs1:9000" {
+				t.Fatalf("target got commands %v, want one REPLICATE from cs1:9000", commands)
+			}
+			if s.replication.busy["cs1"] != 1 || s.replication.busy["cs2"] != 1 {
+				t.Fatalf("busy = %v while the copy is in flight", s.replication.busy)
+			}
+
+			if tt.result != nil {
+				tt.result.CommandId = commands[0].GetId()
+				s.handleCommandResults("cs2", target, []*gfs.CommandResult{tt.result}, now)
+			} else {
+				s.expireCopies(now.Add(commandTimeout + time.Second))
+			}
+
+			r := &s.replication
+			if len(r.copies) != 0 || len(r.cloning) != 0 || len(r.busy) != 0 || r.active != 0 {
+				t.Fatalf("copy bookkeeping not released: copies %v, cloning %v, busy %v, active %d", r.copies, r.cloning, r.busy, r.active)
+			}
+			if len(target.InFlight) != 0 {
+				t.Fatalf("target still awaits %v", target.InFlight)
+			}
+			if got := s.chunkLocations["c1"]; !reflect.DeepEqual(got, tt.wantLocations) {
+				t.Fatalf("locations = %v, want %v", got, tt.wantLocations)
+			}
+			if _, retried := r.tasks["c1"]; retried != (len(tt.wantLocations) < 2) {
+				t.Fatalf("retry queued = %t, want %t", retried, len(tt.wantLocations) < 2)
+			}
+		})
+	}
+}
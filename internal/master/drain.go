@@ -0,0 +1,122 @@
+package master
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/sdudhani/godfs/pkg/gfs"
+)
+
+// DrainChunkserver starts or cancels draining a chunkserver so it can be removed.
+// A draining chunkserver gets no new chunks and its replicas stop counting towards
+// replication goals, so the scheduler copies its chunks elsewhere while it keeps
+// serving reads. ListChunkservers reports when it is safe to remove.
+func (s *Server) DrainChunkserver(ctx context.Context, req *gfs.DrainChunkserverRequest) (*gfs.DrainChunkserverResponse, error) {
+	chunkserverID := req.GetChunkserverId()
+	drain := req.GetDrain()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info, exists := s.chunkservers[chunkserverID]
+	if !exists {
+		return &gfs.DrainChunkserverResponse{
+			Success: false,
+			Message: fmt.Sprintf("Chunkserver %s not found", chunkserverID),
+		}, nil
+	}
+
+	if s.draining[chunkserverID] != drain {
+		err := s.logAndApply(&logRecord{
+			Op:            opSetDraining,
+			ChunkserverID: chunkserverID,
+			Drain:         drain,
+		})
+		if err != nil {
+			log.Printf("Failed to log drain mode of chunkserver %s: %v", chunkserverID, err)
+			return &gfs.DrainChunkserverResponse{
+				Success: false,
+				Message: "Failed to persist drain mode",
+			}, nil
+		}
+		log.Printf("Chunkserver %s drain mode set to %t", chunkserverID, drain)
+	}
+
+	s.syncDrainMode(chunkserverID, info)
+
+	// Start copying chunks off the chunkserver right away
+	s.checkReplication()
+
+	message := fmt.Sprintf("Draining chunkserver %s", chunkserverID)
+	if !drain {
+		message = fmt.Sprintf("Chunkserver %s accepts new chunks again", chunkserverID)
+	}
+	return &gfs.DrainChunkserverResponse{
+		Success: true,
+		Message: message,
+	}, nil
+}
+
+// syncDrainMode tells a chunkserver to refuse or accept new chunks when its drain
+// mode, which it forgets on restart, differs from the master's; callers must hold s.mu
+func (s *Server) syncDrainMode(chunkserverID string, info *ChunkserverInfo) {
+	drain := s.draining[chunkserverID]
+	if cmd := info.queuedCommand(gfs.ChunkserverCommand_DRAIN); cmd != nil {
+		cmd.Drain = drain
+		return
+	}
+	if info.awaitingCommand(gfs.ChunkserverCommand_DRAIN) || info.Stats.GetDraining() == drain {
+		return
+	}
+	s.queueCommand(chunkserverID, &gfs.ChunkserverCommand{Type: gfs.ChunkserverCommand_DRAIN, Drain: drain})
+}
+
+// lastingReplicas returns the live replicas of a chunk that are not being drained;
+// only these count towards its replication goal. Callers must hold s.mu.
+func (s *Server) lastingReplicas(chunkHandle string, now int64) []string {
+	var lasting []string
+	for _, id := range s.liveReplicas(chunkHandle, now) {
+		if !s.draining[id] {
+			lasting = append(lasting, id)
+		}
+	}
+	return lasting
+}
+
+// chunksToDrain counts the chunks of live files on a chunkserver that lack their
+// full replica count on chunkservers that stay; callers must hold s.mu
+func (s *Server) chunksToDrain(chunkserverID string) int {
+	now := time.Now().Unix()
+
+	var count int
+	for _, fileMeta := range s.fileMetadata {
+		goal := fileMeta.replicationGoal()
+		for _, chunkHandle := range fileMeta.ChunkHandles {
+			if containsString(s.chunkLocations[chunkHandle], chunkserverID) && len(s.lastingReplicas(chunkHandle, now)) < goal {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// forgetChunkserver drops a drained chunkserver that went away, along with its
+// drain mode, so it is neither handled as failed nor kept in the metadata again.
+// If it ever returns, it registers as a new chunkserver. Callers must hold s.mu.
+func (s *Server) forgetChunkserver(chunkserverID string) {
+	if s.draining[chunkserverID] {
+		err := s.logAndApply(&logRecord{
+			Op:            opSetDraining,
+			ChunkserverID: chunkserverID,
+			Drain:         false,
+		})
+		if err != nil {
+			log.Printf("Failed to log removal of chunkserver %s: %v", chunkserverID, err)
+			return
+		}
+	}
+	delete(s.chunkservers, chunkserverID)
+	log.Printf("Forgot drained chunkserver %s, which went away", chunkserverID)
+}
@@ -0,0 +1,78 @@
+package master
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sdudhani/godfs/pkg/gfs"
+)
+
+func TestDrainCompletion(t *testing.T) {
+	tests := []struct {
+		name        string
+		locations   []string // of c1, whose file wants 2 replicas
+		gone        bool     // cs1 stops sending heartbeats
+		wantToDrain int32
+		wantKnown   bool // cs1 is still a known chunkserver afterwards
+	}{
+		{name: "replicas not copied yet", locations: []string{"cs1", "cs2"}, wantToDrain: 1, wantKnown: true},
+		{name: "replicas copied", locations: []string{"cs1", "cs2", "cs3"}, wantKnown: true},
+		{name: "no chunks", locations: []string{"cs2", "cs3"}, wantKnown: true},
+		{name: "gone before drained", locations: []string{"cs1", "cs2"}, gone: true, wantToDrain: 1, wantKnown: true},
+		{name: "gone once drained", locations: []string{"cs1", "cs2", "cs3"}, gone: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			s := newTestServer(t, dir)
+			for _, id := range []string{"cs1", "cs2", "cs3"} {
+				s.chunkservers[id] = &ChunkserverInfo{Address: id + ":9000", IsHealthy: true, LastSeen: time.Now().Unix(), InFlight: make(map[uint64]*issuedCommand)}
+			}
+			s.fileMetadata["/f"] = &FileMetadata{ChunkHandles: []string{"c1"}, ReplicationFactor: 2}
+			s.chunkLocations["c1"] = tt.locations
+			s.chunkVersions["c1"] = 1
+
+			if resp, err := s.DrainChunkserver(context.Background(), &gfs.DrainChunkserverRequest{ChunkserverId: "cs1", Drain: true}); err != nil || !resp.GetSuccess() {
+				t.Fatalf("DrainChunkserver() = %s, %v", resp.GetMessage(), err)
+			}
+
+			list, _ := s.ListChunkservers(context.Background(), &gfs.ListChunkserversRequest{})
+			var listed bool
+			for _, status := range list.GetChunkservers() {
+				if status.GetId() != "cs1" {
+					continue
+				}
+				listed = true
+				if !status.GetDraining() || status.GetChunksToDrain() != tt.wantToDrain || status.GetDrained() != (tt.wantToDrain == 0) {
+					t.Fatalf("cs1 draining %t with %d chunks to drain, drained %t; want %d to drain",
+						status.GetDraining(), status.GetChunksToDrain(), status.GetDrained(), tt.wantToDrain)
+				}
+			}
+			if !listed {
+				t.Fatal("ListChunkservers() does not list cs1")
+			}
+
+			if tt.gone {
+				s.chunkservers["cs1"].LastSeen = time.Now().Add(-2 * time.Minute).Unix()
+				s.checkAndHandleFailedChunkservers()
+				// Failed chunkservers are checked again and again
+				s.checkAndHandleFailedChunkservers()
+			}
+
+			if _, known := s.chunkservers["cs1"]; known != tt.wantKnown {
+				t.Fatalf("cs1 known = %t, want %t", known, tt.wantKnown)
+			}
+			if s.draining["cs1"] != tt.wantKnown {
+				t.Fatalf("cs1 draining = %t, want %t", s.draining["cs1"], tt.wantKnown)
+			}
+
+			// Forgetting a chunkserver is logged, so a restarted master does not drain it again
+			s.oplog.file.Close()
+			if restarted := newTestServer(t, dir); restarted.draining["cs1"] != tt.wantKnown {
+				t.Fatalf("cs1 draining after restart = %t, want %t", restarted.draining["cs1"], tt.wantKnown)
+			}
+		})
+	}
+}
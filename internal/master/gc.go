@@ -0,0 +1,89 @@
+package master
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// DefaultDeletedFileRetention is how long deleted files are kept when
+// Config.DeletedFileRetention is unset
+const DefaultDeletedFileRetention = 72 * time.Hour
+
+// gcInterval is how often the master looks for garbage to reclaim
+const gcInterval = time.Minute
+
+// pendingChunkTimeout is how long an allocated chunk may stay uncommitted; after
+// that the allocation is dropped and replicas written for it become orphans
+const pendingChunkTimeout = 24 * time.Hour
+
+// deletedFile is a file removed from the namespace whose chunks are kept until
+// the retention period has passed
+type deletedFile struct {
+	Filename  string
+	Metadata  *FileMetadata
+	DeletedAt int64 // Unix time in nanoseconds
+}
+
+// hiddenName is the name a deleted file is kept under until it is reclaimed
+func hiddenName(filename string, deletedAt int64) string {
+	return fmt.Sprintf(".deleted/%d%s", deletedAt, filename)
+}
+
+// hideFile moves a file out of the namespace under a hidden name; its chunks stay
+// referenced until the hidden file is reclaimed. Callers must hold s.mu.
+func (s *Server) hideFile(filename, name string, deletedAt int64) {
+	fileMeta, exists := s.fileMetadata[filename]
+	if !exists {
+		return
+	}
+
+	s.deletedFiles[name] = &deletedFile{
+		Filename:  filename,
+		Metadata:  fileMeta,
+		DeletedAt: deletedAt,
+	}
+	delete(s.fileMetadata, filename)
+}
+
+// collectGarbagePeriodically reclaims deleted files and abandoned allocations at a fixed interval
+func (s *Server) collectGarbagePeriodically() {
+	ticker := time.NewTicker(gcInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.collectGarbage()
+	}
+}
+
+// collectGarbage forgets deleted files past their retention and uploads that were
+// never committed. Their chunks then become orphans, which chunkservers are told
+// to delete once they report them.
+func (s *Server) collectGarbage() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	var expired []string
+	for name, file := range s.deletedFiles {
+		if now.Sub(time.Unix(0, file.DeletedAt)) > s.deletedFileRetention {
+			expired = append(expired, name)
+		}
+	}
+	if len(expired) > 0 {
+		if err := s.logAndApply(&logRecord{Op: opReclaimFiles, HiddenNames: expired}); err != nil {
+			log.Printf("Failed to log reclamation of deleted files: %v", err)
+		} else {
+			log.Printf("Reclaimed %d deleted files", len(expired))
+		}
+	}
+
+	for chunkHandle, pending := range s.pendingChunks {
+		if now.Sub(pending.AllocatedAt) > pendingChunkTimeout {
+			delete(s.pendingChunks, chunkHandle)
+			log.Printf("Dropped chunk %s of %s, allocated at %s and never committed",
+				chunkHandle, pending.Filename, pending.AllocatedAt.Format(time.RFC3339))
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package master
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sdudhani/godfs/pkg/gfs"
+)
+
+func TestCollectGarbage(t *testing.T) {
+	tests := []struct {
+		name        string
+		deletedAgo  time.Duration // since /f was deleted
+		pendingAgo  time.Duration // since the uncommitted chunk was allocated
+		wantReclaim bool
+		wantDropped bool
+	}{
+		{name: "within retention", deletedAgo: time.Minute, pendingAgo: time.Minute},
+		{name: "past retention", deletedAgo: time.Hour + time.Minute, pendingAgo: time.Minute, wantReclaim: true},
+		{name: "abandoned upload", deletedAgo: time.Minute, pendingAgo: pendingChunkTimeout + time.Minute, wantDropped: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			s := NewServer(Config{MetadataDir: dir, DeletedFileRetention: time.Hour})
+			chunks := allocateChunks(t, s, "/f", 3)
+			if resp, err := s.CommitFile(context.Background(), &gfs.CommitFileRequest{Filename: "/f", Chunks: chunks}); err != nil || !resp.GetSuccess() {
+				t.Fatalf("CommitFile() = %s, %v", resp.GetMessage(), err)
+			}
+			if resp, err := s.DeleteFile(context.Background(), &gfs.DeleteFileRequest{Filename: "/f"}); err != nil || !resp.GetSuccess() {
+				t.Fatalf("DeleteFile() = %s, %v", resp.GetMessage(), err)
+			}
+			chunkHandle := chunks[0].GetChunkHandle()
+			pending := allocateChunks(t, s, "/g", 3)[0].GetChunkHandle()
+
+			// A deleted file is out of the namespace but keeps its chunks until reclaimed
+			if _, exists := s.fileMetadata["/f"]; exists || len(s.deletedFiles) != 1 {
+				t.Fatalf("deleted file not hidden: files %v, deleted %v", s.fileMetadata, s.deletedFiles)
+			}
+			for _, file := range s.deletedFiles {
+				file.DeletedAt = time.Now().Add(-tt.deletedAgo).UnixNano()
+			}
+			s.pendingChunks[pending].AllocatedAt = time.Now().Add(-tt.pendingAgo)
+
+			s.collectGarbage()
+
+			if _, kept := s.chunkLocations[chunkHandle]; kept == tt.wantReclaim {
+				t.Fatalf("chunk of the deleted file kept = %t, want %t", kept, !tt.wantReclaim)
+			}
+			if kept := len(s.deletedFiles) == 1; kept == tt.wantReclaim {
+				t.Fatalf("deleted file kept = %t, want %t", kept, !tt.wantReclaim)
+			}
+			if _, kept := s.pendingChunks[pending]; kept == tt.wantDropped {
+				t.Fatalf("uncommitted chunk kept = %t, want %t", kept, !tt.wantDropped)
+			}
+
+			// Reclaiming is logged, so a restarted master does not bring the file back
+			want := snapshotMetadata(s)
+			s.oplog.file.Close()
+			if got := snapshotMetadata(newTestServer(t, dir)); len(got.DeletedFiles) != len(want.DeletedFiles) || len(got.ChunkLocations) != len(want.ChunkLocations) {
+				t.Fatalf("recovered %d deleted files and %d chunks, want %d and %d", len(got.DeletedFiles), len(got.ChunkLocations), len(want.DeletedFiles), len(want.ChunkLocations))
+			}
+		})
+	}
+}
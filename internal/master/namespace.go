@@ -0,0 +1,449 @@
+package master
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sdudhani/godfs/pkg/gfs"
+)
+
+// rootDir is the top of the namespace; it always exists and is never logged
+const rootDir = "/"
+
+// normalizePath turns a client-supplied path into the canonical form used as a
+// namespace key: absolute, without empty, "." or ".." components and without a
+// trailing slash. A missing leading slash is accepted so plain names map to the root.
+func normalizePath(p string) (string, error) {
+	if p == "" {
+		return "", errors.New("path is empty")
+	}
+	if !utf8.ValidString(p) {
+		return "", fmt.Errorf("path %q is not valid UTF-8", p)
+	}
+
+	var parts []string
+	for _, part := range strings.Split(p, "/") {
+		switch part {
+		case "":
+			// Leading, trailing and repeated slashes
+			continue
+		case ".", "..":
+			return "", fmt.Errorf("path %q must not contain %q", p, part)
+		}
+		if strings.IndexFunc(part, unicode.IsControl) >= 0 {
+			return "", fmt.Errorf("path %q contains a control character", p)
+		}
+		parts = append(parts, part)
+	}
+
+	return rootDir + strings.Join(parts, "/"), nil
+}
+
+// isUnder reports whether p lies strictly inside directory dir
+func isUnder(p, dir string) bool {
+	if dir == rootDir {
+		return p != rootDir
+	}
+	return strings.HasPrefix(p, dir+"/")
+}
+
+// isDir reports whether p is a directory; callers must hold s.mu
+func (s *Server) isDir(p string) bool {
+	return p == rootDir || s.directories[p]
+}
+
+// addParents creates every missing ancestor directory of p; callers must hold s.mu
+func (s *Server) addParents(p string) {
+	for dir := path.Dir(p); dir != rootDir && !s.directories[dir]; dir = path.Dir(dir) {
+		s.directories[dir] = true
+	}
+}
+
+// checkAncestors fails if any ancestor of p is a file; callers must hold s.mu
+func (s *Server) checkAncestors(p string) error {
+	for dir := path.Dir(p); dir != rootDir; dir = path.Dir(dir) {
+		if _, isFile := s.fileMetadata[dir]; isFile {
+			return fmt.Errorf("%s is a file", dir)
+		}
+	}
+	return nil
+}
+
+// checkFilePath fails if a file cannot be stored at p; callers must hold s.mu
+func (s *Server) checkFilePath(p string) error {
+	if s.isDir(p) {
+		return fmt.Errorf("%s is a directory", p)
+	}
+	return s.checkAncestors(p)
+}
+
+// hasChildren reports whether directory dir contains any file or directory; callers must hold s.mu
+func (s *Server) hasChildren(dir string) bool {
+	for filename := range s.fileMetadata {
+		if isUnder(filename, dir) {
+			return true
+		}
+	}
+	for subdir := range s.directories {
+		if isUnder(subdir, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// Mkdir creates a directory, and its missing parents when requested
+func (s *Server) Mkdir(ctx context.Context, req *gfs.MkdirRequest) (*gfs.MkdirResponse, error) {
+	dir, err := normalizePath(req.GetPath())
+	if err != nil {
+		return &gfs.MkdirResponse{
+			Success: false,
+			Message: fmt.Sprintf("Invalid path: %v", err),
+		}, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.isDir(dir) {
+		// Like mkdir -p, asking for parents makes an existing directory a success
+		return &gfs.MkdirResponse{
+			Success: req.GetParents(),
+			Message: fmt.Sprintf("Directory %s already exists", dir),
+		}, nil
+	}
+	if _, isFile := s.fileMetadata[dir]; isFile {
+		return &gfs.MkdirResponse{
+			Success: false,
+			Message: fmt.Sprintf("%s is a file", dir),
+		}, nil
+	}
+	if err := s.checkAncestors(dir); err != nil {
+		return &gfs.MkdirResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+	if parent := path.Dir(dir); !req.GetParents() && !s.isDir(parent) {
+		return &gfs.MkdirResponse{
+			Success: false,
+			Message: fmt.Sprintf("Parent directory %s does not exist", parent),
+		}, nil
+	}
+
+	if err := s.logAndApply(&logRecord{Op: opMkdir, Path: dir}); err != nil {
+		log.Printf("Failed to log creation of directory %s: %v", dir, err)
+		return &gfs.MkdirResponse{
+			Success: false,
+			Message: "Failed to persist directory creation",
+		}, nil
+	}
+
+	log.Printf("Created directory %s", dir)
+
+	return &gfs.MkdirResponse{
+		Success: true,
+		Message: "Directory created successfully",
+	}, nil
+}
+
+// Rmdir removes an empty directory
+func (s *Server) Rmdir(ctx context.Context, req *gfs.RmdirRequest) (*gfs.RmdirResponse, error) {
+	dir, err := normalizePath(req.GetPath())
+	if err != nil {
+		return &gfs.RmdirResponse{
+			Success: false,
+			Message: fmt.Sprintf("Invalid path: %v", err),
+		}, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case dir == rootDir:
+		return &gfs.RmdirResponse{
+			Success: false,
+			Message: "The root directory cannot be removed",
+		}, nil
+	case !s.directories[dir]:
+		return &gfs.RmdirResponse{
+			Success: false,
+			Message: "Directory not found",
+		}, nil
+	case s.hasChildren(dir):
+		return &gfs.RmdirResponse{
+			Success: false,
+			Message: fmt.Sprintf("Directory %s is not empty", dir),
+		}, nil
+	}
+
+	if err := s.logAndApply(&logRecord{Op: opRmdir, Path: dir}); err != nil {
+		log.Printf("Failed to log removal of directory %s: %v", dir, err)
+		return &gfs.RmdirResponse{
+			Success: false,
+			Message: "Failed to persist directory removal",
+		}, nil
+	}
+
+	log.Printf("Removed directory %s", dir)
+
+	return &gfs.RmdirResponse{
+		Success: true,
+		Message: "Directory removed successfully",
+	}, nil
+}
+
+// ListFiles lists the files and directories directly inside a directory
+func (s *Server) ListFiles(ctx context.Context, req *gfs.ListFilesRequest) (*gfs.ListFilesResponse, error) {
+	dir := rootDir
+	if req.GetPath() != "" {
+		var err error
+		if dir, err = normalizePath(req.GetPath()); err != nil {
+			return &gfs.ListFilesResponse{
+				Success: false,
+				Message: fmt.Sprintf("Invalid path: %v", err),
+			}, nil
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.isDir(dir) {
+		message := "Directory not found"
+		if _, isFile := s.fileMetadata[dir]; isFile {
+			message = fmt.Sprintf("%s is not a directory", dir)
+		}
+		return &gfs.ListFilesResponse{
+			Success: false,
+			Message: message,
+		}, nil
+	}
+
+	var files, directories []string
+	for filename := range s.fileMetadata {
+		if path.Dir(filename) == dir {
+			files = append(files, filename)
+		}
+	}
+	for subdir := range s.directories {
+		if path.Dir(subdir) == dir {
+			directories = append(directories, subdir)
+		}
+	}
+	sort.Strings(files)
+	sort.Strings(directories)
+
+	var fileInfos []*gfs.FileInfo
+	if req.GetIncludeAttributes() {
+		for _, filename := range files {
+			fileInfos = append(fileInfos, s.fileInfo(filename, s.fileMetadata[filename]))
+		}
+	}
+
+	return &gfs.ListFilesResponse{
+		Success:     true,
+		Files:       files,
+		Directories: directories,
+		FileInfos:   fileInfos,
+		Message:     fmt.Sprintf("Found %d files and %d directories", len(files), len(directories)),
+	}, nil
+}
+
+// Stat returns the attributes of a file or directory
+func (s *Server) Stat(ctx context.Context, req *gfs.StatRequest) (*gfs.StatResponse, error) {
+	p, err := normalizePath(req.GetPath())
+	if err != nil {
+		return &gfs.StatResponse{
+			Success: false,
+			Message: fmt.Sprintf("Invalid path: %v", err),
+		}, nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.isDir(p) {
+		return &gfs.StatResponse{
+			Success: true,
+			Message: "Directory found",
+			Info:    &gfs.FileInfo{Path: p, IsDirectory: true},
+		}, nil
+	}
+
+	fileMeta, exists := s.fileMetadata[p]
+	if !exists {
+		return &gfs.StatResponse{
+			Success: false,
+			Message: "File not found",
+		}, nil
+	}
+
+	return &gfs.StatResponse{
+		Success: true,
+		Message: "File found",
+		Info:    s.fileInfo(p, fileMeta),
+	}, nil
+}
+
+// fileInfo describes a file and where its chunks live; callers must hold s.mu
+func (s *Server) fileInfo(filename string, fileMeta *FileMetadata) *gfs.FileInfo {
+	chunks := make([]*gfs.ChunkInfo, 0, len(fileMeta.ChunkHandles))
+	for _, chunkHandle := range fileMeta.ChunkHandles {
+		chunks = append(chunks, &gfs.ChunkInfo{
+			ChunkHandle:          chunkHandle,
+			ChunkserverAddresses: s.chunkserverAddresses(s.chunkLocations[chunkHandle]),
+			Version:              s.chunkVersions[chunkHandle],
+		})
+	}
+
+	return &gfs.FileInfo{
+		Path:              filename,
+		Size:              fileMeta.Size,
+		ChunkCount:        int32(len(fileMeta.ChunkHandles)),
+		Chunks:            chunks,
+		CreatedAt:         fileMeta.CreatedAt,
+		ModifiedAt:        fileMeta.ModifiedAt,
+		ReplicationFactor: int32(fileMeta.ReplicationFactor),
+		Checksum:          fileMeta.Checksum,
+	}
+}
+
+// Rename atomically moves a file or a whole directory tree to a new path. Only
+// metadata changes; the chunks stay where they are.
+func (s *Server) Rename(ctx context.Context, req *gfs.RenameRequest) (*gfs.RenameResponse, error) {
+	src, err := normalizePath(req.GetSource())
+	if err != nil {
+		return &gfs.RenameResponse{
+			Success: false,
+			Message: fmt.Sprintf("Invalid source path: %v", err),
+		}, nil
+	}
+	dst, err := normalizePath(req.GetDestination())
+	if err != nil {
+		return &gfs.RenameResponse{
+			Success: false,
+			Message: fmt.Sprintf("Invalid destination path: %v", err),
+		}, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkRename(src, dst, req.GetOverwrite()); err != nil {
+		return &gfs.RenameResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	if src != dst {
+		err := s.logAndApply(&logRecord{
+			Op:        opRename,
+			Path:      src,
+			NewPath:   dst,
+			DeletedAt: time.Now().UnixNano(),
+		})
+		if err != nil {
+			log.Printf("Failed to log rename of %s to %s: %v", src, dst, err)
+			return &gfs.RenameResponse{
+				Success: false,
+				Message: "Failed to persist rename",
+			}, nil
+		}
+	}
+
+	log.Printf("Renamed %s to %s", src, dst)
+
+	return &gfs.RenameResponse{
+		Success: true,
+		Message: "Renamed successfully",
+	}, nil
+}
+
+// checkRename validates moving src to dst; callers must hold s.mu
+func (s *Server) checkRename(src, dst string, overwrite bool) error {
+	_, srcIsFile := s.fileMetadata[src]
+	srcIsDir := s.directories[src]
+
+	switch {
+	case src == rootDir || dst == rootDir:
+		return errors.New("the root directory cannot be renamed")
+	case !srcIsFile && !srcIsDir:
+		return fmt.Errorf("%s not found", src)
+	case src == dst:
+		return nil
+	case srcIsDir && isUnder(dst, src):
+		return fmt.Errorf("cannot move %s into itself", src)
+	}
+
+	if err := s.checkAncestors(dst); err != nil {
+		return err
+	}
+	if parent := path.Dir(dst); !s.isDir(parent) {
+		return fmt.Errorf("parent directory %s does not exist", parent)
+	}
+
+	_, dstIsFile := s.fileMetadata[dst]
+	dstIsDir := s.directories[dst]
+	if !dstIsFile && !dstIsDir {
+		return nil
+	}
+
+	switch {
+	case !overwrite:
+		return fmt.Errorf("%s already exists", dst)
+	case srcIsFile && dstIsDir:
+		return fmt.Errorf("cannot overwrite directory %s with a file", dst)
+	case srcIsDir && dstIsFile:
+		return fmt.Errorf("cannot overwrite file %s with a directory", dst)
+	case dstIsDir && s.hasChildren(dst):
+		return fmt.Errorf("directory %s is not empty", dst)
+	}
+	return nil
+}
+
+// applyRename moves src and everything below it to dst, replacing whatever dst
+// held; callers must hold s.mu
+func (s *Server) applyRename(src, dst string, deletedAt int64) {
+	// An overwritten file is deleted like any other, an overwritten directory is empty
+	s.hideFile(dst, hiddenName(dst, deletedAt), deletedAt)
+	delete(s.directories, dst)
+
+	if fileMeta, isFile := s.fileMetadata[src]; isFile {
+		delete(s.fileMetadata, src)
+		s.fileMetadata[dst] = fileMeta
+	} else {
+		// Collect first so the maps are not modified while being ranged over
+		movedFiles := make(map[string]*FileMetadata)
+		for filename, fileMeta := range s.fileMetadata {
+			if isUnder(filename, src) {
+				movedFiles[filename] = fileMeta
+			}
+		}
+		var movedDirs []string
+		for dir := range s.directories {
+			if dir == src || isUnder(dir, src) {
+				movedDirs = append(movedDirs, dir)
+			}
+		}
+
+		for filename, fileMeta := range movedFiles {
+			delete(s.fileMetadata, filename)
+			s.fileMetadata[dst+strings.TrimPrefix(filename, src)] = fileMeta
+		}
+		for _, dir := range movedDirs {
+			delete(s.directories, dir)
+			s.directories[dst+strings.TrimPrefix(dir, src)] = true
+		}
+	}
+}
@@ -0,0 +1,361 @@
+package master
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/sdudhani/godfs/pkg/gfs"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestNormalizePath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{path: "/", want: "/"},
+		{path: "//", want: "/"},
+		{path: "a", want: "/a"},
+		{path: "/a/b", want: "/a/b"},
+		{path: "/a/b/", want: "/a/b"},
+		{path: "//a///b//", want: "/a/b"},
+		{path: "/a b/c", want: "/a b/c"},
+		{path: "/ünï/ç", want: "/ünï/ç"},
+		{path: "", wantErr: true},
+		{path: ".", wantErr: true},
+		{path: "/a/./b", wantErr: true},
+		{path: "/a/../b", wantErr: true},
+		{path: "..", wantErr: true},
+		{path: "/a\nb", wantErr: true},
+		{path: "/a\x00", wantErr: true},
+		{path: "/\xff", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := normalizePath(tt.path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("normalizePath(%q) error = %v, want error %v", tt.path, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("normalizePath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+// newNamespace builds a master holding the given files and directories
+func newNamespace(files []string, dirs []string) *Server {
+	s := &Server{
+		fileMetadata:   make(map[string]*FileMetadata),
+		directories:    make(map[string]bool),
+		deletedFiles:   make(map[string]*deletedFile),
+		chunkLocations: make(map[string][]string),
+		chunkVersions:  make(map[string]uint64),
+		chunkSizes:     make(map[string]int64),
+	}
+	addPaths(s, files, dirs)
+	return s
+}
+
+// addPaths adds files and directories, with their parents, to a master's namespace
+func addPaths(s *Server, files []string, dirs []string) {
+	for _, dir := range dirs {
+		s.directories[dir] = true
+		s.addParents(dir)
+	}
+	for _, filename := range files {
+		s.fileMetadata[filename] = &FileMetadata{}
+		s.addParents(filename)
+	}
+}
+
+// namespacePaths lists every file and directory, sorted
+func namespacePaths(s *Server) (files, dirs []string) {
+	for filename := range s.fileMetadata {
+		files = append(files, filename)
+	}
+	for dir := range s.directories {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(files)
+	sort.Strings(dirs)
+	return files, dirs
+}
+
+func TestRename(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     []string
+		dirs      []string
+		src, dst  string
+		overwrite bool
+		wantErr   bool
+		wantFiles []string
+		wantDirs  []string
+	}{
+		{
+			name:      "file",
+			files:     []string{"/a/f"},
+			src:       "/a/f",
+			dst:       "/a/g",
+			wantFiles: []string{"/a/g"},
+			wantDirs:  []string{"/a"},
+		},
+		{
+			name:      "file to another directory",
+			files:     []string{"/a/f"},
+			dirs:      []string{"/b"},
+			src:       "/a/f",
+			dst:       "/b/f",
+			wantFiles: []string{"/b/f"},
+			wantDirs:  []string{"/a", "/b"},
+		},
+		{
+			name:      "directory tree",
+			files:     []string{"/a/f", "/a/b/g", "/ab/h"},
+			dirs:      []string{"/a/b/c"},
+			src:       "/a",
+			dst:       "/x",
+			wantFiles: []string{"/ab/h", "/x/b/g", "/x/f"},
+			wantDirs:  []string{"/ab", "/x", "/x/b", "/x/b/c"},
+		},
+		{
+			name:      "onto itself",
+			files:     []string{"/a/f"},
+			src:       "/a/f",
+			dst:       "/a/f",
+			wantFiles: []string{"/a/f"},
+			wantDirs:  []string{"/a"},
+		},
+		{
+			name:      "overwrite file",
+			files:     []string{"/f", "/g"},
+			src:       "/f",
+			dst:       "/g",
+			overwrite: true,
+			wantFiles: []string{"/g"},
+		},
+		{
+			name:      "overwrite empty directory",
+			dirs:      []string{"/a/b", "/c"},
+			src:       "/a",
+			dst:       "/c",
+			overwrite: true,
+			wantDirs:  []string{"/c", "/c/b"},
+		},
+		{name: "root source", dirs: []string{"/a"}, src: "/", dst: "/a", wantErr: true},
+		{name: "root destination", dirs: []string{"/a"}, src: "/a", dst: "/", wantErr: true},
+		{name: "missing source", src: "/f", dst: "/g", wantErr: true},
+		{name: "into itself", dirs: []string{"/a"}, src: "/a", dst: "/a/b", wantErr: true},
+		{name: "existing destination", files: []string{"/f", "/g"}, src: "/f", dst: "/g", wantErr: true},
+		{name: "under a file", files: []string{"/f", "/g"}, src: "/f", dst: "/g/f", wantErr: true},
+		{name: "missing destination parent", files: []string{"/f"}, src: "/f", dst: "/a/f", wantErr: true},
+		{name: "missing destination grandparent", dirs: []string{"/d"}, src: "/d", dst: "/a/b/d", wantErr: true},
+		{name: "file over directory", files: []string{"/f"}, dirs: []string{"/d"}, src: "/f", dst: "/d", overwrite: true, wantErr: true},
+		{name: "directory over file", files: []string{"/f"}, dirs: []string{"/d"}, src: "/d", dst: "/f", overwrite: true, wantErr: true},
+		{name: "over non-empty directory", files: []string{"/c/f"}, dirs: []string{"/a"}, src: "/a", dst: "/c", overwrite: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newNamespace(tt.files, tt.dirs)
+			wantFiles, wantDirs := tt.wantFiles, tt.wantDirs
+			if tt.wantErr {
+				wantFiles, wantDirs = namespacePaths(s)
+			}
+
+			err := s.checkRename(tt.src, tt.dst, tt.overwrite)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkRename(%s, %s) error = %v, want error %v", tt.src, tt.dst, err, tt.wantErr)
+			}
+			if err == nil && tt.src != tt.dst {
+				s.applyRename(tt.src, tt.dst, 1)
+			}
+
+			files, dirs := namespacePaths(s)
+			if !reflect.DeepEqual(files, wantFiles) || !reflect.DeepEqual(dirs, wantDirs) {
+				t.Fatalf("namespace is files %v, dirs %v; want files %v, dirs %v", files, dirs, wantFiles, wantDirs)
+			}
+		})
+	}
+}
+
+func TestMkdir(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    []string
+		dirs     []string
+		path     string
+		parents  bool
+		wantErr  bool
+		wantDirs []string
+	}{
+		{name: "in root", path: "/a", wantDirs: []string{"/a"}},
+		{name: "in a directory", dirs: []string{"/a"}, path: "/a/b", wantDirs: []string{"/a", "/a/b"}},
+		{name: "with missing parents", path: "/a/b/c", parents: true, wantDirs: []string{"/a", "/a/b", "/a/b/c"}},
+		{name: "existing, with parents", dirs: []string{"/a"}, path: "/a/", parents: true, wantDirs: []string{"/a"}},
+		{name: "existing", dirs: []string{"/a"}, path: "/a", wantErr: true},
+		{name: "root", path: "/", wantErr: true},
+		{name: "missing parent", path: "/a/b", wantErr: true},
+		{name: "over a file", files: []string{"/f"}, path: "/f", parents: true, wantErr: true},
+		{name: "under a file", files: []string{"/f"}, path: "/f/d", parents: true, wantErr: true},
+		{name: "invalid path", path: "/a/../b", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, t.TempDir())
+			addPaths(s, tt.files, tt.dirs)
+			wantFiles, wantDirs := namespacePaths(s)
+			if !tt.wantErr {
+				wantDirs = tt.wantDirs
+			}
+
+			resp, err := s.Mkdir(context.Background(), &gfs.MkdirRequest{Path: tt.path, Parents: tt.parents})
+			if err != nil || resp.GetSuccess() == tt.wantErr {
+				t.Fatalf("Mkdir(%s) = %t (%s), %v; want success %t", tt.path, resp.GetSuccess(), resp.GetMessage(), err, !tt.wantErr)
+			}
+
+			files, dirs := namespacePaths(s)
+			if !reflect.DeepEqual(files, wantFiles) || !reflect.DeepEqual(dirs, wantDirs) {
+				t.Fatalf("namespace is files %v, dirs %v; want files %v, dirs %v", files, dirs, wantFiles, wantDirs)
+			}
+		})
+	}
+}
+
+func TestRmdir(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    []string
+		dirs     []string
+		path     string
+		wantErr  bool
+		wantDirs []string
+	}{
+		{name: "empty", dirs: []string{"/a/b"}, path: "/a/b", wantDirs: []string{"/a"}},
+		{name: "with a subdirectory", dirs: []string{"/a/b"}, path: "/a", wantErr: true},
+		{name: "with a file", files: []string{"/a/f"}, path: "/a", wantErr: true},
+		{name: "root", path: "/", wantErr: true},
+		{name: "missing", path: "/a", wantErr: true},
+		{name: "a file", files: []string{"/f"}, path: "/f", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, t.TempDir())
+			addPaths(s, tt.files, tt.dirs)
+			wantFiles, wantDirs := namespacePaths(s)
+			if !tt.wantErr {
+				wantDirs = tt.wantDirs
+			}
+
+			resp, err := s.Rmdir(context.Background(), &gfs.RmdirRequest{Path: tt.path})
+			if err != nil || resp.GetSuccess() == tt.wantErr {
+				t.Fatalf("Rmdir(%s) = %t (%s), %v; want success %t", tt.path, resp.GetSuccess(), resp.GetMessage(), err, !tt.wantErr)
+			}
+
+			files, dirs := namespacePaths(s)
+			if !reflect.DeepEqual(files, wantFiles) || !reflect.DeepEqual(dirs, wantDirs) {
+				t.Fatalf("namespace is files %v, dirs %v; want files %v, dirs %v", files, dirs, wantFiles, wantDirs)
+			}
+		})
+	}
+}
+
+func TestListFiles(t *testing.T) {
+	s := newNamespace([]string{"/f", "/a/g", "/a/b/h", "/ab/i"}, []string{"/a/c", "/e"})
+
+	tests := []struct {
+		path      string
+		wantErr   bool
+		wantFiles []string
+		wantDirs  []string
+	}{
+		{path: "", wantFiles: []string{"/f"}, wantDirs: []string{"/a", "/ab", "/e"}},
+		{path: "/", wantFiles: []string{"/f"}, wantDirs: []string{"/a", "/ab", "/e"}},
+		{path: "/a", wantFiles: []string{"/a/g"}, wantDirs: []string{"/a/b", "/a/c"}},
+		{path: "/a/b/", wantFiles: []string{"/a/b/h"}},
+		{path: "/e"},
+		{path: "/missing", wantErr: true},
+		{path: "/f", wantErr: true},
+		{path: "/a/..", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		resp, err := s.ListFiles(context.Background(), &gfs.ListFilesRequest{Path: tt.path})
+		if err != nil || resp.GetSuccess() == tt.wantErr {
+			t.Errorf("ListFiles(%q) = %t (%s), %v; want success %t", tt.path, resp.GetSuccess(), resp.GetMessage(), err, !tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(resp.GetFiles(), tt.wantFiles) || !reflect.DeepEqual(resp.GetDirectories(), tt.wantDirs) {
+			t.Errorf("ListFiles(%q) = files %v, dirs %v; want files %v, dirs %v", tt.path, resp.GetFiles(), resp.GetDirectories(), tt.wantFiles, tt.wantDirs)
+		}
+	}
+}
+
+func TestStat(t *testing.T) {
+	s := newNamespace([]string{"/a/f"}, nil)
+	s.chunkservers = map[string]*ChunkserverInfo{"cs1": {Address: "cs1:9001"}}
+	s.fileMetadata["/a/f"] = &FileMetadata{
+		ChunkHandles:      []string{"c1", "c2"},
+		Size:              15,
+		CreatedAt:         1,
+		ModifiedAt:        2,
+		ReplicationFactor: 2,
+		Checksum:          "00c0ffee",
+	}
+	s.chunkLocations = map[string][]string{"c1": {"cs1"}, "c2": {"cs1", "cs2"}}
+	s.chunkVersions = map[string]uint64{"c1": 1, "c2": 3}
+
+	file := &gfs.FileInfo{
+		Path:       "/a/f",
+		Size:       15,
+		ChunkCount: 2,
+		Chunks: []*gfs.ChunkInfo{
+			{ChunkHandle: "c1", ChunkserverAddresses: []string{"cs1:9001"}, Version: 1},
+			// cs2 has not registered, so it has no address to hand out
+			{ChunkHandle: "c2", ChunkserverAddresses: []string{"cs1:9001"}, Version: 3},
+		},
+		CreatedAt:         1,
+		ModifiedAt:        2,
+		ReplicationFactor: 2,
+		Checksum:          "00c0ffee",
+	}
+
+	tests := []struct {
+		path    string
+		want    *gfs.FileInfo
+		wantErr bool
+	}{
+		{path: "/a/f", want: file},
+		{path: "a//f/", want: file},
+		{path: "/a", want: &gfs.FileInfo{Path: "/a", IsDirectory: true}},
+		{path: "/", want: &gfs.FileInfo{Path: "/", IsDirectory: true}},
+		{path: "/a/g", wantErr: true},
+		{path: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		resp, err := s.Stat(context.Background(), &gfs.StatRequest{Path: tt.path})
+		if err != nil || resp.GetSuccess() == tt.wantErr {
+			t.Errorf("Stat(%q) = %t (%s), %v; want success %t", tt.path, resp.GetSuccess(), resp.GetMessage(), err, !tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !proto.Equal(resp.GetInfo(), tt.want) {
+			t.Errorf("Stat(%q) = %v, want %v", tt.path, resp.GetInfo(), tt.want)
+		}
+	}
+
+	// Listing with attributes describes each file as Stat does
+	resp, err := s.ListFiles(context.Background(), &gfs.ListFilesRequest{Path: "/a", IncludeAttributes: true})
+	if err != nil || len(resp.GetFileInfos()) != 1 || !proto.Equal(resp.GetFileInfos()[0], file) {
+		t.Fatalf("ListFiles() file infos = %v, %v; want [%v]", resp.GetFileInfos(), err, file)
+	}
+	if resp, _ := s.ListFiles(context.Background(), &gfs.ListFilesRequest{Path: "/a"}); len(resp.GetFileInfos()) != 0 {
+		t.Fatalf("ListFiles() without attributes returned file infos %v", resp.GetFileInfos())
+	}
+}
@@ -0,0 +1,280 @@
+package master
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+const (
+	opLogFileName      = "oplog.jsonl"
+	checkpointFileName = "checkpoint.json"
+)
+
+// opType identifies the kind of namespace mutation stored in a log record
+type opType string
+
+const (
+	opUploadFile   opType = "upload_file"
+	opSetLocations opType = "set_locations"
+
+	// opHideFile moves a deleted file out of the namespace under a hidden name;
+	// opReclaimFiles forgets hidden files once their retention has passed
+	opHideFile     opType = "hide_file"
+	opReclaimFiles opType = "reclaim_files"
+
+	// opDeleteDir hides every file below a directory and removes the whole tree
+	opDeleteDir opType = "delete_dir"
+
+	opMkdir opType = "mkdir"
+	opRmdir opType = "rmdir"
+
+	// opRename moves a file or directory tree from Path to NewPath, hiding a
+	// file it overwrites
+	opRename opType = "rename"
+
+	// opReserveHandles durably raises the chunk handle limit; a restarted
+	// master resumes allocation from the highest reserved limit
+	opReserveHandles opType = "reserve_handles"
+
+	// opSetVersions raises chunk versions to ones reported by chunkservers
+	opSetVersions opType = "set_versions"
+
+	// opSetReplication changes the replication factor of a file
+	opSetReplication opType = "set_replication"
+
+	// opSetDraining starts or cancels draining a chunkserver for removal
+	opSetDraining opType = "set_draining"
+)
+
+// logRecord is a single entry of the operation log
+type logRecord struct {
+	Seq            uint64              `json:"seq"`
+	Op             opType              `json:"op"`
+	Filename       string              `json:"filename,omitempty"`
+	Path           string              `json:"path,omitempty"`
+	NewPath        string              `json:"new_path,omitempty"`
+	Metadata       *FileMetadata       `json:"metadata,omitempty"`
+	ChunkLocations map[string][]string `json:"chunk_locations,omitempty"`
+	ChunkVersions  map[string]uint64   `json:"chunk_versions,omitempty"`
+	HandleLimit    uint64              `json:"handle_limit,omitempty"`
+	HiddenName     string              `json:"hidden_name,omitempty"`
+	DeletedAt      int64               `json:"deleted_at,omitempty"`
+	HiddenNames    []string            `json:"hidden_names,omitempty"`
+
+	ReplicationFactor int `json:"replication_factor,omitempty"`
+
+	ChunkserverID string `json:"chunkserver_id,omitempty"`
+	Drain         bool   `json:"drain,omitempty"`
+}
+
+// checkpoint is a compact snapshot of the master metadata
+type checkpoint struct {
+	LastSeq          uint64                   `json:"last_seq"`
+	FileMetadata     map[string]*FileMetadata `json:"file_metadata"`
+	DeletedFiles     map[string]*deletedFile  `json:"deleted_files"`
+	Directories      map[string]bool          `json:"directories"`
+	ChunkLocations   map[string][]string      `json:"chunk_locations"`
+	ChunkVersions    map[string]uint64        `json:"chunk_versions"`
+	ChunkHandleLimit uint64                   `json:"chunk_handle_limit"`
+	Draining         map[string]bool          `json:"draining,omitempty"`
+}
+
+// opLog is an append-only, fsynced log of namespace mutations
+type opLog struct {
+	dir     string
+	file    *os.File
+	lastSeq uint64 // sequence number of the last record written or replayed
+	pending int    // records appended since the last checkpoint
+
+	// broken is set when a failed append could not be rolled back; the log
+	// may then hold a record that was never applied, so nothing more is written
+	broken error
+}
+
+// openOpLog opens (creating if needed) the operation log in dir
+func openOpLog(dir string) (*opLog, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create metadata directory: %w", err)
+	}
+
+	file, err := os.OpenFile(filepath.Join(dir, opLogFileName), os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("open operation log: %w", err)
+	}
+
+	return &opLog{dir: dir, file: file}, nil
+}
+
+// loadCheckpoint reads the latest checkpoint, returning nil if none exists
+func (l *opLog) loadCheckpoint() (*checkpoint, error) {
+	data, err := os.ReadFile(filepath.Join(l.dir, checkpointFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read checkpoint: %w", err)
+	}
+
+	var cp checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("decode checkpoint: %w", err)
+	}
+
+	l.lastSeq = cp.LastSeq
+	return &cp, nil
+}
+
+// replay calls apply for every record newer than the loaded checkpoint
+func (l *opLog) replay(apply func(*logRecord)) error {
+	if _, err := l.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("seek operation log: %w", err)
+	}
+
+	var offset int64
+	reader := bufio.NewReader(l.file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(line) > 0 {
+				// A crash in the middle of an append leaves a torn final record;
+				// cut it off so new records start on a clean line
+				log.Printf("Discarding truncated record at end of operation log")
+				if err := l.file.Truncate(offset); err != nil {
+					return fmt.Errorf("truncate operation log: %w", err)
+				}
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read operation log: %w", err)
+		}
+
+		var rec logRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return fmt.Errorf("decode operation log record: %w", err)
+		}
+
+		// Records already folded into the checkpoint are skipped
+		if rec.Seq <= l.lastSeq {
+			offset += int64(len(line))
+			continue
+		}
+
+		apply(&rec)
+		l.lastSeq = rec.Seq
+		l.pending++
+		offset += int64(len(line))
+	}
+}
+
+// append durably writes a record to the log, assigning its sequence number
+func (l *opLog) append(rec *logRecord) error {
+	if l.broken != nil {
+		return l.broken
+	}
+	rec.Seq = l.lastSeq + 1
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("encode log record: %w", err)
+	}
+	data = append(data, '\n')
+
+	info, err := l.file.Stat()
+	if err != nil {
+		return fmt.Errorf("stat operation log: %w", err)
+	}
+	offset := info.Size()
+
+	if _, err := l.file.Write(data); err != nil {
+		return l.rollback(offset, fmt.Errorf("write operation log: %w", err))
+	}
+	if err := l.file.Sync(); err != nil {
+		return l.rollback(offset, fmt.Errorf("sync operation log: %w", err))
+	}
+
+	l.lastSeq = rec.Seq
+	l.pending++
+	return nil
+}
+
+// rollback cuts off whatever part of a failed append reached the log, so the
+// record's sequence number is never seen twice. If that fails too, the log
+// refuses further appends.
+func (l *opLog) rollback(offset int64, err error) error {
+	if truncErr := l.file.Truncate(offset); truncErr != nil {
+		l.broken = fmt.Errorf("operation log is unusable after a failed append: %w", truncErr)
+		return errors.Join(err, l.broken)
+	}
+	if syncErr := l.file.Sync(); syncErr != nil {
+		l.broken = fmt.Errorf("operation log is unusable after a failed append: %w", syncErr)
+		return errors.Join(err, l.broken)
+	}
+	return err
+}
+
+// checkpoint atomically persists cp and truncates the records it covers
+func (l *opLog) checkpoint(cp *checkpoint) error {
+	cp.LastSeq = l.lastSeq
+
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("encode checkpoint: %w", err)
+	}
+
+	// Write to a temp file and rename so a crash never leaves a partial checkpoint
+	tmpPath := filepath.Join(l.dir, checkpointFileName+".tmp")
+	tmp, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("create checkpoint: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("sync checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close checkpoint: %w", err)
+	}
+	if err := os.Rename(tmpPath, filepath.Join(l.dir, checkpointFileName)); err != nil {
+		return fmt.Errorf("install checkpoint: %w", err)
+	}
+	if err := syncDir(l.dir); err != nil {
+		return err
+	}
+
+	// Records up to LastSeq are now redundant; replay skips them even if
+	// we crash before the truncation below completes
+	if err := l.file.Truncate(0); err != nil {
+		return fmt.Errorf("truncate operation log: %w", err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("sync operation log: %w", err)
+	}
+
+	l.pending = 0
+	return nil
+}
+
+// syncDir fsyncs a directory so renames within it are durable
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("open directory %s: %w", dir, err)
+	}
+	defer d.Close()
+
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("sync directory %s: %w", dir, err)
+	}
+	return nil
+}
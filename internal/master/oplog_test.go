@@ -0,0 +1,157 @@
+package master
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// appendRecords appends a mkdir record for each path
+func appendRecords(t *testing.T, l *opLog, paths ...string) {
+	t.Helper()
+	for _, p := range paths {
+		if err := l.append(&logRecord{Op: opMkdir, Path: p}); err != nil {
+			t.Fatalf("append %s: %v", p, err)
+		}
+	}
+}
+
+// replayedSeqs replays the log in dir and returns the sequence numbers it applied
+func replayedSeqs(t *testing.T, dir string) (*opLog, []uint64) {
+	t.Helper()
+	l, err := openOpLog(dir)
+	if err != nil {
+		t.Fatalf("open operation log: %v", err)
+	}
+	t.Cleanup(func() { l.file.Close() })
+
+	var seqs []uint64
+	if err := l.replay(func(rec *logRecord) { seqs = append(seqs, rec.Seq) }); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	return l, seqs
+}
+
+func TestReplayTornTail(t *testing.T) {
+	tests := []struct {
+		name string
+		tail string
+	}{
+		{"clean end", ""},
+		{"partial record", `{"seq":3,"op":"mk`},
+		{"record without newline", `{"seq":3,"op":"mkdir","path":"/c"}`},
+		{"lone brace", "{"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			l, err := openOpLog(dir)
+			if err != nil {
+				t.Fatalf("open operation log: %v", err)
+			}
+			appendRecords(t, l, "/a", "/b")
+			l.file.Close()
+
+			logPath := filepath.Join(dir, opLogFileName)
+			clean, err := os.Stat(logPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			file, err := os.OpenFile(logPath, os.O_WRONLY|os.O_APPEND, 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := file.WriteString(tt.tail); err != nil {
+				t.Fatal(err)
+			}
+			file.Close()
+
+			l, seqs := replayedSeqs(t, dir)
+			if want := []uint64{1, 2}; !reflect.DeepEqual(seqs, want) {
+				t.Fatalf("replayed %v, want %v", seqs, want)
+			}
+			if info, err := os.Stat(logPath); err != nil || info.Size() != clean.Size() {
+				t.Fatalf("log is %d bytes after replay (err %v), want the torn tail cut to %d", info.Size(), err, clean.Size())
+			}
+
+			// New records must land on a clean line and survive another replay
+			appendRecords(t, l, "/c")
+			l.file.Close()
+			if _, seqs := replayedSeqs(t, dir); !reflect.DeepEqual(seqs, []uint64{1, 2, 3}) {
+				t.Fatalf("replayed %v after appending, want [1 2 3]", seqs)
+			}
+		})
+	}
+}
+
+func TestReplaySkipsCheckpointedRecords(t *testing.T) {
+	tests := []struct {
+		name    string
+		lastSeq uint64
+		want    []uint64
+	}{
+		{"no checkpoint", 0, []uint64{1, 2, 3}},
+		{"checkpoint mid log", 2, []uint64{3}},
+		{"checkpoint at end", 3, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			l, err := openOpLog(dir)
+			if err != nil {
+				t.Fatalf("open operation log: %v", err)
+			}
+			appendRecords(t, l, "/a", "/b", "/c")
+			l.file.Close()
+
+			// A crash between installing a checkpoint and truncating the log
+			// leaves records the checkpoint already covers
+			l, err = openOpLog(dir)
+			if err != nil {
+				t.Fatalf("open operation log: %v", err)
+			}
+			t.Cleanup(func() { l.file.Close() })
+			l.lastSeq = tt.lastSeq
+
+			var seqs []uint64
+			if err := l.replay(func(rec *logRecord) { seqs = append(seqs, rec.Seq) }); err != nil {
+				t.Fatalf("replay: %v", err)
+			}
+			if !reflect.DeepEqual(seqs, tt.want) {
+				t.Fatalf("replayed %v, want %v", seqs, tt.want)
+			}
+		})
+	}
+}
+
+func TestFailedAppendDoesNotReuseSeq(t *testing.T) {
+	l, err := openOpLog(t.TempDir())
+	if err != nil {
+		t.Fatalf("open operation log: %v", err)
+	}
+	appendRecords(t, l, "/a")
+
+	// A read-only handle fails both the write and its rollback
+	l.file.Close()
+	readOnly, err := os.Open(l.file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer readOnly.Close()
+	l.file = readOnly
+
+	for i := 0; i < 2; i++ {
+		if err := l.append(&logRecord{Op: opMkdir, Path: "/b"}); err == nil {
+			t.Fatalf("append %d succeeded on a read-only log", i)
+		}
+		if l.lastSeq != 1 {
+			t.Fatalf("lastSeq is %d after a failed append, want 1", l.lastSeq)
+		}
+	}
+	if l.broken == nil {
+		t.Fatal("log still accepts appends after a rollback failed")
+	}
+}
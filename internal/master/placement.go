@@ -0,0 +1,244 @@
+package master
+
+import (
+	"math/rand/v2"
+	"sort"
+
+	"github.com/sdudhani/godfs/pkg/gfs"
+)
+
+// PlacementPolicy decides which chunkservers hold the replicas of a chunk
+type PlacementPolicy interface {
+	// Place picks up to count of the candidates for new replicas of a chunk
+	// whose other replicas live on existing, best first
+	Place(candidates, existing []*PlacementNode, count int) []string
+
+	// Trim picks count of the replicas to remove from a chunk with too many
+	Trim(replicas []*PlacementNode, count int) []string
+}
+
+// PlacementNode is a chunkserver as a placement policy sees it
+type PlacementNode struct {
+	ID       string
+	Topology *gfs.Topology
+
+	// Utilization is the fraction of the chunkserver's storage in use, counting
+	// chunks placed on it since its last heartbeat
+	Utilization float64
+
+	// FreeBytes is the space left after those chunks; 0 if unknown
+	FreeBytes int64
+}
+
+// failureDomains returns the zone, rack and host keys of a node. A chunkserver
+// without a host label is assumed to be alone on its machine.
+func (n *PlacementNode) failureDomains() [3]string {
+	zone, rack, host := n.Topology.GetZone(), n.Topology.GetRack(), n.Topology.GetHost()
+	if host == "" {
+		host = "id:" + n.ID
+	}
+	return [3]string{zone, zone + "/" + rack, zone + "/" + rack + "/" + host}
+}
+
+// sharedDomains counts how many of others share each failure domain of n
+func (n *PlacementNode) sharedDomains(others []*PlacementNode) [3]int {
+	domains := n.failureDomains()
+	var shared [3]int
+	for _, other := range others {
+		if other == n {
+			continue
+		}
+		otherDomains := other.failureDomains()
+		for level := range domains {
+			if domains[level] == otherDomains[level] {
+				shared[level]++
+			}
+		}
+	}
+	return shared
+}
+
+// compareShared orders domain overlaps, zones first
+func compareShared(a, b [3]int) int {
+	for level := range a {
+		if a[level] != b[level] {
+			return a[level] - b[level]
+		}
+	}
+	return 0
+}
+
+// SpreadPlacement puts replicas of a chunk in different zones, then racks, then
+// hosts. Among the chunkservers that spread a chunk equally well it picks at
+// random, weighted by free space, or the least utilized one if no free space is
+// known. It removes replicas from the most crowded domains, fullest chunkservers
+// first.
+type SpreadPlacement struct{}
+
+// Place implements PlacementPolicy
+func (SpreadPlacement) Place(candidates, existing []*PlacementNode, count int) []string {
+	placed := append([]*PlacementNode(nil), existing...)
+	remaining := append([]*PlacementNode(nil), candidates...)
+
+	var targets []string
+	for len(targets) < count && len(remaining) > 0 {
+		// Candidates sharing the fewest failure domains with the replicas so far
+		var tier []int
+		var tierShared [3]int
+		for i, node := range remaining {
+			shared := node.sharedDomains(placed)
+			if c := compareShared(shared, tierShared); len(tier) == 0 || c < 0 {
+				tier, tierShared = []int{i}, shared
+			} else if c == 0 {
+				tier = append(tier, i)
+			}
+		}
+		best := pickByFreeSpace(remaining, tier)
+
+		targets = append(targets, remaining[best].ID)
+		placed = append(placed, remaining[best])
+		remaining = append(remaining[:best], remaining[best+1:]...)
+	}
+	return targets
+}
+
+// Trim implements PlacementPolicy
+func (SpreadPlacement) Trim(replicas []*PlacementNode, count int) []string {
+	remaining := append([]*PlacementNode(nil), replicas...)
+
+	var surplus []string
+	for len(surplus) < count && len(remaining) > 0 {
+		worst := 0
+		worstShared := remaining[0].sharedDomains(remaining)
+		for i := 1; i < len(remaining); i++ {
+			shared := remaining[i].sharedDomains(remaining)
+			if c := compareShared(shared, worstShared); c > 0 || c == 0 && lessUtilized(remaining[worst], remaining[i]) {
+				worst, worstShared = i, shared
+			}
+		}
+
+		surplus = append(surplus, remaining[worst].ID)
+		remaining = append(remaining[:worst], remaining[worst+1:]...)
+	}
+	return surplus
+}
+
+// pickByFreeSpace picks one of the nodes at indexes with a probability
+// proportional to its free space, falling back to the least utilized
+func pickByFreeSpace(nodes []*PlacementNode, indexes []int) int {
+	var totalFree int64
+	for _, i := range indexes {
+		totalFree += nodes[i].FreeBytes
+	}
+
+	if totalFree <= 0 {
+		best := indexes[0]
+		for _, i := range indexes[1:] {
+			if lessUtilized(nodes[i], nodes[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+
+	r := rand.Int64N(totalFree)
+	for _, i := range indexes {
+		if r < nodes[i].FreeBytes {
+			return i
+		}
+		r -= nodes[i].FreeBytes
+	}
+	return indexes[len(indexes)-1]
+}
+
+// lessUtilized orders nodes by utilization, breaking ties by ID so placement is stable
+func lessUtilized(a, b *PlacementNode) bool {
+	if a.Utilization != b.Utilization {
+		return a.Utilization < b.Utilization
+	}
+	return a.ID < b.ID
+}
+
+// placementNodes describes chunkservers for the placement policy; callers must hold s.mu
+func (s *Server) placementNodes(chunkserverIDs []string) []*PlacementNode {
+	nodes := make([]*PlacementNode, 0, len(chunkserverIDs))
+	for _, id := range chunkserverIDs {
+		info, exists := s.chunkservers[id]
+		if !exists {
+			continue
+		}
+		nodes = append(nodes, &PlacementNode{
+			ID:          id,
+			Topology:    info.Topology,
+			Utilization: info.utilization(),
+			FreeBytes:   info.freeBytes(),
+		})
+	}
+	return nodes
+}
+
+// utilization is the fraction of the disk in use, counting chunks placed since
+// the last heartbeat. Without a reported disk size, only chunk data counts;
+// chunkservers that reported no storage count as empty.
+func (info *ChunkserverInfo) utilization() float64 {
+	if total := info.Stats.GetTotalBytes(); total > 0 {
+		return float64(total-info.Stats.GetFreeBytes()+info.PlacedBytes) / float64(total)
+	}
+
+	used := info.Stats.GetUsedBytes() + info.PlacedBytes
+	capacity := info.Stats.GetUsedBytes() + info.Stats.GetFreeBytes()
+	if capacity <= 0 {
+		return 0
+	}
+	return float64(used) / float64(capacity)
+}
+
+// freeBytes is the free disk space left after chunks placed since the last
+// heartbeat; 0 if unknown
+func (info *ChunkserverInfo) freeBytes() int64 {
+	return max(info.Stats.GetFreeBytes()-info.PlacedBytes, 0)
+}
+
+// full reports whether the chunkserver has no room for new chunks: its disk is
+// above highWaterMark or cannot hold another chunkSize bytes. Chunkservers that
+// do not report their disk size are never full.
+func (info *ChunkserverInfo) full(highWaterMark float64, chunkSize int64) bool {
+	if info.Stats.GetTotalBytes() <= 0 {
+		return false
+	}
+	return info.utilization() >= highWaterMark || info.freeBytes() < chunkSize
+}
+
+// placeReplicas picks up to count available chunkservers for new replicas of a
+// chunk that already lives on existing, and charges each one a chunk until its
+// next heartbeat; callers must hold s.mu
+func (s *Server) placeReplicas(existing []string, count int, now int64) []string {
+	var candidates []string
+	for id, info := range s.chunkservers {
+		if info.available(now) && !containsString(existing, id) {
+			candidates = append(candidates, id)
+		}
+	}
+	return s.placeOn(candidates, existing, count)
+}
+
+// placeOn lets the placement policy pick up to count of the candidates with room
+// for a chunk that are not being drained, and charges each pick a chunk until its
+// next heartbeat; callers must hold s.mu
+func (s *Server) placeOn(candidates, existing []string, count int) []string {
+	var roomy []string
+	for _, id := range candidates {
+		if !s.draining[id] && !s.chunkservers[id].full(s.highWaterMark, s.chunkSize) {
+			roomy = append(roomy, id)
+		}
+	}
+
+	// Map iteration order must not leak into placement
+	sort.Strings(roomy)
+
+	targets := s.placement.Place(s.placementNodes(roomy), s.placementNodes(existing), count)
+	for _, id := range targets {
+		s.chunkservers[id].PlacedBytes += s.chunkSize
+	}
+	return targets
+}
@@ -0,0 +1,186 @@
+package master
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sdudhani/godfs/pkg/gfs"
+)
+
+// node describes a chunkserver at zone/rack/host
+func node(id, zone, rack, host string, utilization float64) *PlacementNode {
+	return &PlacementNode{
+		ID:          id,
+		Topology:    &gfs.Topology{Zone: zone, Rack: rack, Host: host},
+		Utilization: utilization,
+	}
+}
+
+func TestSpreadPlacementPlace(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []*PlacementNode
+		existing   []*PlacementNode
+		count      int
+		want       []string
+	}{
+		{
+			name: "one per zone before a second in any",
+			candidates: []*PlacementNode{
+				node("a1", "a", "r1", "h1", 0),
+				node("a2", "a", "r2", "h2", 0),
+				node("b1", "b", "r1", "h1", 0.5),
+				node("c1", "c", "r1", "h1", 0.9),
+			},
+			count: 3,
+			want:  []string{"a1", "b1", "c1"},
+		},
+		{
+			name: "avoid zones of existing replicas",
+			candidates: []*PlacementNode{
+				node("a2", "a", "r2", "h2", 0),
+				node("b1", "b", "r1", "h1", 0.8),
+			},
+			existing: []*PlacementNode{node("a1", "a", "r1", "h1", 0)},
+			count:    1,
+			want:     []string{"b1"},
+		},
+		{
+			name: "other rack before same rack",
+			candidates: []*PlacementNode{
+				node("x", "a", "r1", "h2", 0),
+				node("y", "a", "r2", "h3", 0.7),
+			},
+			existing: []*PlacementNode{node("e", "a", "r1", "h1", 0)},
+			count:    1,
+			want:     []string{"y"},
+		},
+		{
+			name: "other host before same host",
+			candidates: []*PlacementNode{
+				node("x", "a", "r1", "h1", 0),
+				node("y", "a", "r1", "h2", 0.7),
+			},
+			existing: []*PlacementNode{node("e", "a", "r1", "h1", 0)},
+			count:    1,
+			want:     []string{"y"},
+		},
+		{
+			name: "least utilized among equals",
+			candidates: []*PlacementNode{
+				node("x", "", "", "", 0.6),
+				node("y", "", "", "", 0.2),
+				node("z", "", "", "", 0.4),
+			},
+			count: 2,
+			want:  []string{"y", "z"},
+		},
+		{
+			name: "ties broken by ID",
+			candidates: []*PlacementNode{
+				node("z", "", "", "", 0),
+				node("y", "", "", "", 0),
+			},
+			count: 1,
+			want:  []string{"y"},
+		},
+		{
+			name:       "fewer candidates than asked",
+			candidates: []*PlacementNode{node("x", "", "", "", 0)},
+			count:      3,
+			want:       []string{"x"},
+		},
+		{
+			name:  "no candidates",
+			count: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SpreadPlacement{}.Place(tt.candidates, tt.existing, tt.count)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Place picked %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpreadPlacementPlaceWeightsFreeSpace(t *testing.T) {
+	candidates := []*PlacementNode{
+		{ID: "empty", FreeBytes: 0},
+		{ID: "roomy", FreeBytes: 100},
+	}
+	for i := 0; i < 100; i++ {
+		if got := (SpreadPlacement{}).Place(candidates, nil, 1); !reflect.DeepEqual(got, []string{"roomy"}) {
+			t.Fatalf("Place picked %v, want only chunkservers with free space", got)
+		}
+	}
+}
+
+func TestSpreadPlacementTrim(t *testing.T) {
+	tests := []struct {
+		name     string
+		replicas []*PlacementNode
+		count    int
+		want     []string
+	}{
+		{
+			name: "most crowded zone first",
+			replicas: []*PlacementNode{
+				node("a1", "a", "r1", "h1", 0.1),
+				node("a2", "a", "r2", "h2", 0.2),
+				node("b1", "b", "r1", "h1", 0.9),
+			},
+			count: 1,
+			want:  []string{"a2"},
+		},
+		{
+			name: "shared rack before shared zone",
+			replicas: []*PlacementNode{
+				node("x", "a", "r1", "h1", 0.1),
+				node("y", "a", "r1", "h2", 0.2),
+				node("z", "a", "r2", "h3", 0.9),
+			},
+			count: 1,
+			want:  []string{"y"},
+		},
+		{
+			name: "fullest among equals",
+			replicas: []*PlacementNode{
+				node("x", "", "", "", 0.3),
+				node("y", "", "", "", 0.9),
+				node("z", "", "", "", 0.5),
+			},
+			count: 2,
+			want:  []string{"y", "z"},
+		},
+		{
+			name: "recounts crowding after each pick",
+			replicas: []*PlacementNode{
+				node("a1", "a", "r1", "h1", 0.1),
+				node("a2", "a", "r2", "h2", 0.2),
+				node("a3", "a", "r3", "h3", 0.3),
+				node("b1", "b", "r1", "h1", 0.9),
+				node("b2", "b", "r2", "h2", 0.8),
+			},
+			count: 2,
+			want:  []string{"a3", "b1"},
+		},
+		{
+			name:     "more than there are",
+			replicas: []*PlacementNode{node("x", "", "", "", 0)},
+			count:    2,
+			want:     []string{"x"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SpreadPlacement{}.Trim(tt.replicas, tt.count)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Trim picked %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
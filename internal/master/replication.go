@@ -0,0 +1,531 @@
+package master
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/sdudhani/godfs/pkg/gfs"
+)
+
+const (
+	// maxConcurrentClones caps chunk copies in flight across the cluster
+	maxConcurrentClones = 8
+
+	// maxClonesPerChunkserver caps the copies one chunkserver takes part in,
+	// as source or target, so re-replication does not starve client traffic
+	maxClonesPerChunkserver = 2
+
+	// replicationRetryDelay is the wait after a failed copy; it doubles with
+	// every further failure up to maxReplicationRetryDelay
+	replicationRetryDelay    = 10 * time.Second
+	maxReplicationRetryDelay = 5 * time.Minute
+
+	// replicationPollInterval is how often queued copies are started
+	replicationPollInterval = time.Second
+
+	// maxReplicationFactor is the most replicas a file may ask for
+	maxReplicationFactor = 16
+)
+
+// replicationTask is a chunk with fewer replicas than its goal
+type replicationTask struct {
+	ChunkHandle string
+	Goal        int       // Replicas the chunk should have
+	Deficit     int       // Replicas missing when last checked; larger deficits go first
+	NotBefore   time.Time // Earliest time of the next attempt
+	index       int       // Position in the heap
+}
+
+// replicationQueue is a heap of tasks ordered by deficit, then by readiness
+type replicationQueue []*replicationTask
+
+func (q replicationQueue) Len() int { return len(q) }
+
+func (q replicationQueue) Less(i, j int) bool {
+	if q[i].Deficit != q[j].Deficit {
+		return q[i].Deficit > q[j].Deficit
+	}
+	return q[i].NotBefore.Before(q[j].NotBefore)
+}
+
+func (q replicationQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *replicationQueue) Push(x any) {
+	task := x.(*replicationTask)
+	task.index = len(*q)
+	*q = append(*q, task)
+}
+
+func (q *replicationQueue) Pop() any {
+	old := *q
+	task := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return task
+}
+
+// chunkCopy is a chunk being copied between chunkservers by a REPLICATE command,
+// to restore a missing replica or, with Move set, for the balancer
+type chunkCopy struct {
+	ChunkHandle string
+	Version     uint64
+	Goal        int // Replicas the chunk should have; unused for moves
+	SourceID    string
+	TargetID    string
+	Move        *chunkMove
+	QueuedAt    time.Time
+}
+
+// replicationState is the re-replication scheduler's bookkeeping; it is guarded by s.mu
+type replicationState struct {
+	queue    replicationQueue
+	tasks    map[string]*replicationTask // chunk handle -> queued task
+	attempts map[string]int              // chunk handle -> failed copies since it last met its goal
+	copies   map[uint64]*chunkCopy       // REPLICATE command ID -> copy in flight
+	cloning  map[string][]string         // chunk handle -> targets of copies in flight
+	busy     map[string]int              // chunkserver ID -> copies it takes part in
+	active   int                         // re-replication copies in flight
+}
+
+// newReplicationState creates an empty scheduler state
+func newReplicationState() replicationState {
+	return replicationState{
+		tasks:    make(map[string]*replicationTask),
+		attempts: make(map[string]int),
+		copies:   make(map[uint64]*chunkCopy),
+		cloning:  make(map[string][]string),
+		busy:     make(map[string]int),
+	}
+}
+
+// liveReplicas returns the chunkservers holding a chunk that are currently available;
+// callers must hold s.mu
+func (s *Server) liveReplicas(chunkHandle string, now int64) []string {
+	var live []string
+	for _, id := range s.chunkLocations[chunkHandle] {
+		if info, exists := s.chunkservers[id]; exists && info.available(now) {
+			live = append(live, id)
+		}
+	}
+	return live
+}
+
+// enqueueReplication queues a chunk whose lasting and in-flight replicas fall short
+// of goal, or refreshes its priority if it is already queued; callers must hold s.mu
+func (s *Server) enqueueReplication(chunkHandle string, goal int, now int64) {
+	r := &s.replication
+	deficit := goal - len(s.lastingReplicas(chunkHandle, now)) - len(r.cloning[chunkHandle])
+
+	if task, queued := r.tasks[chunkHandle]; queued {
+		task.Goal = goal
+		task.Deficit = deficit
+		heap.Fix(&r.queue, task.index)
+		return
+	}
+	if deficit <= 0 {
+		return
+	}
+
+	task := &replicationTask{ChunkHandle: chunkHandle, Goal: goal, Deficit: deficit}
+	r.tasks[chunkHandle] = task
+	heap.Push(&r.queue, task)
+}
+
+// replicationFactor resolves the replication factor requested for filename: 0 keeps
+// the factor of the file being overwritten, or the default; callers must hold s.mu
+func (s *Server) replicationFactor(filename string, requested int32) (int, error) {
+	if requested < 0 || requested > maxReplicationFactor {
+		return 0, fmt.Errorf("replication factor must be between 1 and %d", maxReplicationFactor)
+	}
+	if requested > 0 {
+		return int(requested), nil
+	}
+	if fileMeta, exists := s.fileMetadata[filename]; exists && fileMeta.ReplicationFactor > 0 {
+		return fileMeta.ReplicationFactor, nil
+	}
+	return defaultReplicationFactor, nil
+}
+
+// SetReplication changes the replication factor of a file. Missing replicas are
+// added by the scheduler and surplus ones removed in the background.
+func (s *Server) SetReplication(ctx context.Context, req *gfs.SetReplicationRequest) (*gfs.SetReplicationResponse, error) {
+	filename, err := normalizePath(req.GetPath())
+	if err != nil {
+		return &gfs.SetReplicationResponse{
+			Success: false,
+			Message: fmt.Sprintf("Invalid path: %v", err),
+		}, nil
+	}
+
+	factor := req.GetReplicationFactor()
+	if factor < 1 || factor > maxReplicationFactor {
+		return &gfs.SetReplicationResponse{
+			Success: false,
+			Message: fmt.Sprintf("Replication factor must be between 1 and %d", maxReplicationFactor),
+		}, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fileMeta, exists := s.fileMetadata[filename]
+	if !exists {
+		message := fmt.Sprintf("File %s not found", filename)
+		if s.isDir(filename) {
+			message = fmt.Sprintf("%s is a directory", filename)
+		}
+		return &gfs.SetReplicationResponse{
+			Success: false,
+			Message: message,
+		}, nil
+	}
+
+	if fileMeta.ReplicationFactor != int(factor) {
+		err := s.logAndApply(&logRecord{
+			Op:                opSetReplication,
+			Filename:          filename,
+			ReplicationFactor: int(factor),
+		})
+		if err != nil {
+			log.Printf("Failed to log replication factor of %s: %v", filename, err)
+			return &gfs.SetReplicationResponse{
+				Success: false,
+				Message: "Failed to persist replication factor",
+			}, nil
+		}
+		log.Printf("Set replication factor of %s to %d", filename, factor)
+	}
+
+	// Start converging right away once chunk locations can be trusted
+	if s.locationsPruned {
+		s.convergeReplication(fileMeta, time.Now().Unix())
+	}
+
+	return &gfs.SetReplicationResponse{
+		Success: true,
+		Message: fmt.Sprintf("Replication factor of %s set to %d", filename, factor),
+	}, nil
+}
+
+// checkReplication queues every chunk of a live file that has fewer replicas than its
+// file's replication factor and trims chunks that have more; callers must hold s.mu
+func (s *Server) checkReplication() {
+	// Until chunkservers had time to report, missing locations do not mean missing replicas
+	if !s.locationsPruned {
+		return
+	}
+
+	now := time.Now().Unix()
+	var lost int
+	for _, fileMeta := range s.fileMetadata {
+		lost += s.convergeReplication(fileMeta, now)
+	}
+	// Deleted chunks need no more copies
+	for chunkHandle := range s.replication.attempts {
+		if _, exists := s.chunkVersions[chunkHandle]; !exists {
+			delete(s.replication.attempts, chunkHandle)
+		}
+	}
+
+	if lost > 0 {
+		log.Printf("%d chunks have no available replica", lost)
+	}
+}
+
+// convergeReplication moves the replica count of every chunk of a file towards its
+// replication factor and returns how many chunks have no replica left to copy;
+// callers must hold s.mu
+func (s *Server) convergeReplication(fileMeta *FileMetadata, now int64) int {
+	goal := fileMeta.replicationGoal()
+
+	var lost int
+	for _, chunkHandle := range fileMeta.ChunkHandles {
+		live := s.liveReplicas(chunkHandle, now)
+		lasting := s.lastingReplicas(chunkHandle, now)
+		cloning := len(s.replication.cloning[chunkHandle])
+		if len(lasting) >= goal {
+			// Copies failing from here on start backing off afresh
+			delete(s.replication.attempts, chunkHandle)
+		}
+
+		switch {
+		case len(live) == 0 && cloning == 0:
+			// Nothing left to copy from; a returning chunkserver may still have it
+			lost++
+		case len(lasting) > goal && cloning == 0:
+			// Replicas on draining chunkservers stay until those are removed
+			s.trimReplicas(chunkHandle, lasting, goal)
+		default:
+			s.enqueueReplication(chunkHandle, goal, now)
+		}
+	}
+	return lost
+}
+
+// trimReplicas removes the replicas beyond goal that the placement policy picks and
+// tells their chunkservers to delete them; callers must hold s.mu
+func (s *Server) trimReplicas(chunkHandle string, live []string, goal int) {
+	surplus := s.placement.Trim(s.placementNodes(live), len(live)-goal)
+
+	var locations []string
+	for _, id := range s.chunkLocations[chunkHandle] {
+		if !containsString(surplus, id) {
+			locations = append(locations, id)
+		}
+	}
+
+	err := s.logAndApply(&logRecord{
+		Op:             opSetLocations,
+		ChunkLocations: map[string][]string{chunkHandle: locations},
+	})
+	if err != nil {
+		log.Printf("Failed to log new locations of chunk %s: %v", chunkHandle, err)
+		return
+	}
+
+	for _, id := range surplus {
+		s.queueChunkDeletion(id, chunkHandle)
+	}
+	log.Printf("Removed %d surplus replicas of chunk %s from %v", len(surplus), chunkHandle, surplus)
+}
+
+// replicatePeriodically starts queued chunk copies as capacity allows
+func (s *Server) replicatePeriodically() {
+	ticker := time.NewTicker(replicationPollInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.scheduleReplication()
+	}
+}
+
+// scheduleReplication starts copies for the most under-replicated chunks that are
+// due, within the global and per-chunkserver limits
+func (s *Server) scheduleReplication() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := &s.replication
+	now := time.Now()
+	var waiting []*replicationTask
+
+	// Copies whose command was never acknowledged free their slots
+	s.expireCopies(now)
+
+	for r.queue.Len() > 0 && r.active < maxConcurrentClones {
+		task := heap.Pop(&r.queue).(*replicationTask)
+		if task.NotBefore.After(now) {
+			waiting = append(waiting, task)
+			continue
+		}
+
+		// Draining chunkservers still serve as sources
+		live := s.liveReplicas(task.ChunkHandle, now.Unix())
+		task.Deficit = task.Goal - len(s.lastingReplicas(task.ChunkHandle, now.Unix())) - len(r.cloning[task.ChunkHandle])
+		if task.Deficit <= 0 || len(live) == 0 {
+			delete(r.tasks, task.ChunkHandle)
+			continue
+		}
+
+		sourceID, targetIDs := s.pickCloneServers(task, live, now.Unix())
+		if sourceID == "" || len(targetIDs) == 0 {
+			// Every candidate is busy or none is available; try again later
+			task.NotBefore = now.Add(replicationRetryDelay)
+			waiting = append(waiting, task)
+			continue
+		}
+
+		for _, targetID := range targetIDs {
+			started := s.startCopy(&chunkCopy{
+				ChunkHandle: task.ChunkHandle,
+				Version:     s.chunkVersions[task.ChunkHandle],
+				Goal:        task.Goal,
+				SourceID:    sourceID,
+				TargetID:    targetID,
+			}, now)
+			if started {
+				r.active++
+				task.Deficit--
+			}
+		}
+
+		if task.Deficit > 0 {
+			waiting = append(waiting, task)
+		} else {
+			delete(r.tasks, task.ChunkHandle)
+		}
+	}
+
+	for _, task := range waiting {
+		heap.Push(&r.queue, task)
+	}
+}
+
+// pickCloneServers chooses the least busy live replica as the source and up to
+// task.Deficit available chunkservers without the chunk as targets, honoring the
+// copy limits and the placement policy; callers must hold s.mu
+func (s *Server) pickCloneServers(task *replicationTask, live []string, now int64) (string, []string) {
+	r := &s.replication
+
+	var sourceID string
+	for _, id := range live {
+		if r.busy[id] < maxClonesPerChunkserver && (sourceID == "" || r.busy[id] < r.busy[sourceID]) {
+			sourceID = id
+		}
+	}
+	if sourceID == "" {
+		return "", nil
+	}
+
+	locations := s.chunkLocations[task.ChunkHandle]
+	var candidates []string
+	for id, info := range s.chunkservers {
+		if id == sourceID || containsString(locations, id) || containsString(r.cloning[task.ChunkHandle], id) || info.deletionPending(task.ChunkHandle) {
+			continue
+		}
+		if info.available(now) && r.busy[id] < maxClonesPerChunkserver {
+			candidates = append(candidates, id)
+		}
+	}
+
+	count := task.Deficit
+	if free := maxConcurrentClones - r.active; count > free {
+		count = free
+	}
+
+	// New replicas should land in failure domains the chunk is not in yet
+	existing := append(s.lastingReplicas(task.ChunkHandle, now), r.cloning[task.ChunkHandle]...)
+	return sourceID, s.placeOn(candidates, existing, count)
+}
+
+// startCopy has the copy's target chunkserver pull the chunk from its source with
+// a REPLICATE command sent in the target's next heartbeat response. The copy
+// counts against the copy limits until the target acknowledges the command or
+// it times out. Callers must hold s.mu.
+func (s *Server) startCopy(cp *chunkCopy, now time.Time) bool {
+	cmd := &gfs.ChunkserverCommand{
+		Type:          gfs.ChunkserverCommand_REPLICATE,
+		ChunkHandle:   cp.ChunkHandle,
+		Version:       cp.Version,
+		SourceAddress: s.chunkservers[cp.SourceID].Address,
+	}
+	if !s.queueCommand(cp.TargetID, cmd) {
+		return false
+	}
+
+	r := &s.replication
+	cp.QueuedAt = now
+	r.copies[cmd.GetId()] = cp
+	r.cloning[cp.ChunkHandle] = append(r.cloning[cp.ChunkHandle], cp.TargetID)
+	r.busy[cp.SourceID]++
+	r.busy[cp.TargetID]++
+	return true
+}
+
+// finishCopy releases the copy started by a REPLICATE command and records its
+// outcome: the bytes the target copied, or why it failed. The master only adds
+// the new location once the copy succeeded. Callers must hold s.mu.
+func (s *Server) finishCopy(commandID uint64, size int64, err error) {
+	r := &s.replication
+	cp, exists := r.copies[commandID]
+	if !exists {
+		return
+	}
+	delete(r.copies, commandID)
+
+	if targets := removeString(r.cloning[cp.ChunkHandle], cp.TargetID); len(targets) > 0 {
+		r.cloning[cp.ChunkHandle] = targets
+	} else {
+		delete(r.cloning, cp.ChunkHandle)
+	}
+	decrement(r.busy, cp.SourceID)
+	decrement(r.busy, cp.TargetID)
+
+	if cp.Move != nil {
+		s.finishMove(cp.Move, size, err)
+		return
+	}
+
+	r.active--
+	if err != nil {
+		log.Printf("Failed to copy chunk %s from %s to %s: %v", cp.ChunkHandle, cp.SourceID, cp.TargetID, err)
+		s.retryReplication(cp.ChunkHandle, cp.Goal)
+		return
+	}
+	s.addReplica(cp.ChunkHandle, cp.Version, cp.TargetID)
+}
+
+// expireCopies gives up on copies whose target has not acknowledged them within
+// commandTimeout; callers must hold s.mu
+func (s *Server) expireCopies(now time.Time) {
+	for id, cp := range s.replication.copies {
+		if now.Sub(cp.QueuedAt) > commandTimeout {
+			s.forgetCommand(cp.TargetID, id)
+			s.finishCopy(id, 0, fmt.Errorf("not acknowledged within %s", commandTimeout))
+		}
+	}
+}
+
+// abandonCopies gives up on the copies a failed chunkserver was to make; callers
+// must hold s.mu
+func (s *Server) abandonCopies(failedID string) {
+	for id, cp := range s.replication.copies {
+		if cp.TargetID == failedID {
+			s.forgetCommand(failedID, id)
+			s.finishCopy(id, 0, fmt.Errorf("chunkserver %s failed", failedID))
+		}
+	}
+}
+
+// retryReplication requeues a chunk after a failed copy, backing off exponentially
+// with the copies of it that failed since it last met its goal; callers must hold s.mu
+func (s *Server) retryReplication(chunkHandle string, goal int) {
+	r := &s.replication
+
+	task, queued := r.tasks[chunkHandle]
+	if !queued {
+		s.enqueueReplication(chunkHandle, goal, time.Now().Unix())
+		if task, queued = r.tasks[chunkHandle]; !queued {
+			return
+		}
+	}
+
+	r.attempts[chunkHandle]++
+	delay := replicationRetryDelay << (r.attempts[chunkHandle] - 1)
+	if delay > maxReplicationRetryDelay || delay <= 0 {
+		delay = maxReplicationRetryDelay
+	}
+	task.NotBefore = time.Now().Add(delay)
+	heap.Fix(&r.queue, task.index)
+}
+
+// replicationStatus summarizes the re-replication queue; callers must hold s.mu
+func (s *Server) replicationStatus() *gfs.ReplicationStatus {
+	r := &s.replication
+
+	var missing int32
+	for _, task := range r.queue {
+		missing += int32(task.Deficit)
+	}
+	return &gfs.ReplicationStatus{
+		QueuedChunks:    int32(r.queue.Len()),
+		MissingReplicas: missing,
+		ActiveCopies:    int32(r.active),
+	}
+}
+
+// decrement lowers a counter, removing it once it reaches zero
+func decrement(counts map[string]int, key string) {
+	if counts[key] <= 1 {
+		delete(counts, key)
+		return
+	}
+	counts[key]--
+}
@@ -0,0 +1,130 @@
+package master
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sdudhani/godfs/pkg/gfs"
+)
+
+// copyChunk starts a copy of chunk c1 from cs1 to cs2 and finishes it with err,
+// as if the target had reported the outcome
+func copyChunk(t *testing.T, s *Server, err error) {
+	t.Helper()
+	if !s.startCopy(&chunkCopy{ChunkHandle: "c1", Version: 1, Goal: 2, SourceID: "cs1", TargetID: "cs2"}, time.Now()) {
+		t.Fatal("startCopy() = false")
+	}
+	s.replication.active++
+	for id := range s.replication.copies {
+		s.forgetCommand("cs2", id)
+		s.finishCopy(id, 10, err)
+	}
+}
+
+func TestReplicationBackoff(t *testing.T) {
+	s := newTestServer(t, t.TempDir())
+	for _, id := range []string{"cs1", "cs2"} {
+		s.chunkservers[id] = &ChunkserverInfo{Address: id + ":9000", IsHealthy: true, LastSeen: time.Now().Unix(), InFlight: make(map[uint64]*issuedCommand)}
+	}
+	s.fileMetadata["/f"] = &FileMetadata{ChunkHandles: []string{"c1"}, ReplicationFactor: 2}
+	s.chunkLocations["c1"] = []string{"cs1"}
+	s.chunkVersions["c1"] = 1
+	s.locationsPruned = true
+
+	// Every failure doubles the wait, although the task leaves the queue while copying
+	for _, want := range []time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second, 80 * time.Second} {
+		failedAt := time.Now()
+		copyChunk(t, s, errors.New("source unreachable"))
+
+		task, queued := s.replication.tasks["c1"]
+		if !queued {
+			t.Fatal("failed copy was not retried")
+		}
+		if got := task.NotBefore.Sub(failedAt); got < want || got > want+time.Second {
+			t.Fatalf("retry after %s, want %s", got, want)
+		}
+		delete(s.replication.tasks, "c1")
+		s.replication.queue = nil
+	}
+
+	// Once the chunk meets its goal, later failures back off from the start
+	copyChunk(t, s, nil)
+	s.checkReplication()
+	if attempts, kept := s.replication.attempts["c1"]; kept {
+		t.Fatalf("%d failed attempts kept after the chunk met its goal", attempts)
+	}
+}
+
+func TestSetReplication(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        string
+		factor      int32
+		wantErr     bool
+		wantFactor  int
+		wantReplica int // replicas of c1 left in place
+		wantQueued  int // replicas of c1 queued to be added
+	}{
+		{name: "unchanged", path: "/d/f", factor: 2, wantFactor: 2, wantReplica: 2},
+		{name: "raised", path: "/d/f", factor: 4, wantFactor: 4, wantReplica: 2, wantQueued: 2},
+		{name: "lowered", path: "/d/f", factor: 1, wantFactor: 1, wantReplica: 1},
+		{name: "zero", path: "/d/f", factor: 0, wantErr: true},
+		{name: "too many", path: "/d/f", factor: maxReplicationFactor + 1, wantErr: true},
+		{name: "missing file", path: "/d/g", factor: 3, wantErr: true},
+		{name: "directory", path: "/d", factor: 3, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, t.TempDir())
+			for _, id := range []string{"cs1", "cs2", "cs3", "cs4"} {
+				s.chunkservers[id] = &ChunkserverInfo{Address: id + ":9000", IsHealthy: true, LastSeen: time.Now().Unix(), InFlight: make(map[uint64]*issuedCommand)}
+			}
+			addPaths(s, []string{"/d/f"}, nil)
+			s.fileMetadata["/d/f"] = &FileMetadata{ChunkHandles: []string{"c1"}, ReplicationFactor: 2}
+			s.chunkLocations["c1"] = []string{"cs1", "cs2"}
+			s.chunkVersions["c1"] = 1
+			s.locationsPruned = true
+
+			resp, err := s.SetReplication(context.Background(), &gfs.SetReplicationRequest{Path: tt.path, ReplicationFactor: tt.factor})
+			if err != nil || resp.GetSuccess() == tt.wantErr {
+				t.Fatalf("SetReplication(%s, %d) = %t (%s), %v; want success %t", tt.path, tt.factor, resp.GetSuccess(), resp.GetMessage(), err, !tt.wantErr)
+			}
+			// A refused change leaves the file as it was
+			wantFactor, wantReplica := tt.wantFactor, tt.wantReplica
+			if tt.wantErr {
+				wantFactor, wantReplica = 2, 2
+			}
+
+			if got := s.fileMetadata["/d/f"].ReplicationFactor; got != wantFactor {
+				t.Fatalf("replication factor = %d, want %d", got, wantFactor)
+			}
+			if got := s.chunkLocations["c1"]; len(got) != wantReplica {
+				t.Fatalf("replicas = %v, want %d", got, wantReplica)
+			}
+			var queued int
+			if task, exists := s.replication.tasks["c1"]; exists {
+				queued = task.Deficit
+			}
+			if queued != tt.wantQueued {
+				t.Fatalf("replicas queued = %d, want %d", queued, tt.wantQueued)
+			}
+
+			// Surplus replicas are deleted from the chunkservers that held them
+			var deleted int
+			for _, id := range []string{"cs1", "cs2"} {
+				if s.chunkservers[id].deletionPending("c1") {
+					deleted++
+					if containsString(s.chunkLocations["c1"], id) {
+						t.Fatalf("%s is told to delete c1 but still listed", id)
+					}
+				}
+			}
+			if want := 2 - wantReplica; deleted != want {
+				t.Fatalf("%d replicas queued for deletion, want %d", deleted, want)
+			}
+		})
+	}
+}
@@ -1,27 +1,58 @@
 package master
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"log"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/sdudhani/godfs/pkg/gfs"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 // FileMetadata represents metadata for a file
 type FileMetadata struct {
-	ChunkHandles []string
-	Size         int64
+	ChunkHandles      []string
+	Size              int64
+	CreatedAt         int64  // Unix time of the first upload to this path
+	ModifiedAt        int64  // Unix time of the latest upload
+	ReplicationFactor int    // Number of replicas each chunk should have
+	Checksum          string // CRC-32C of the contents, empty if unknown
+}
+
+// replicationGoal is the number of replicas each chunk of the file should have
+func (fileMeta *FileMetadata) replicationGoal() int {
+	if fileMeta.ReplicationFactor <= 0 {
+		return defaultReplicationFactor
+	}
+	return fileMeta.ReplicationFactor
 }
 
 // ChunkserverInfo represents information about a chunkserver
 type ChunkserverInfo struct {
-	Address   string
-	LastSeen  int64
-	IsHealthy bool
+	Address    string // Latest advertised address; may change across restarts
+	LastSeen   int64
+	IsHealthy  bool
+	LastReport int64 // Unix time of the last full chunk report, 0 if none yet
+
+	// Commands wait for the next heartbeat response; InFlight holds those sent
+	// but not yet acknowledged, by command ID
+	Commands []*gfs.ChunkserverCommand
+	InFlight map[uint64]*issuedCommand
+
+	// Stats is the storage and load reported with the latest heartbeat
+	Stats *gfs.ChunkserverStats
+
+	// PlacedBytes estimates the chunks placed on the chunkserver since Stats was
+	// reported, so one upload does not pile every chunk onto the same servers
+	PlacedBytes int64
+
+	// Topology is the failure domains the chunkserver reported
+	Topology *gfs.Topology
 }
 
 // Server implements the gRPC Master server
@@ -31,161 +62,531 @@ type Server struct {
 	// Metadata storage
 	mu             sync.RWMutex
 	fileMetadata   map[string]*FileMetadata // filename -> metadata
-	chunkLocations map[string][]string      // chunkHandle -> chunkserver addresses
+	directories    map[string]bool          // directory path -> exists; the root is implicit
+	deletedFiles   map[string]*deletedFile  // hidden name -> deleted file awaiting reclamation
+	chunkLocations map[string][]string      // chunkHandle -> chunkserver IDs
+	chunkVersions  map[string]uint64        // chunkHandle -> current version
+	pendingChunks  map[string]*pendingChunk // chunkHandle -> allocation awaiting CommitFile
+	chunkSizes     map[string]int64         // chunkHandle -> size chunkservers last reported; not logged
 
 	// Chunkserver management
-	chunkservers map[string]*ChunkserverInfo // address -> info
+	chunkservers  map[string]*ChunkserverInfo // chunkserver ID -> info
+	draining      map[string]bool             // chunkserver ID -> being drained for removal
+	nextCommandID uint64                      // ID of the last command queued for a chunkserver
+
+	// Connections to chunkservers, reused across calls; connMu is never held
+	// while acquiring mu
+	connMu           sync.Mutex
+	chunkserverConns map[string]*grpc.ClientConn // address -> connection
+
+	// Re-replication queue and copies in flight
+	replication replicationState
+
+	// Moves that even out disk utilization
+	balancer balancerState
+
+	// Chooses chunkservers for new replicas and surplus replicas to remove
+	placement PlacementPolicy
+
+	// Disk utilization at which a chunkserver gets no new chunks
+	highWaterMark float64
+
+	// Durable log of namespace mutations
+	oplog *opLog
+
+	// Chunk handle allocation; handles below chunkHandleLimit are reserved in the log
+	nextChunkHandle  uint64
+	chunkHandleLimit uint64
+
+	// Recovered locations are pruned once chunkservers had time to report
+	startedAt       time.Time
+	locationsPruned bool
+
+	// Maximum number of bytes stored in a single chunk
+	chunkSize int64
+
+	// How long deleted files keep their chunks before they are reclaimed
+	deletedFileRetention time.Duration
 }
 
-// NewServer creates a new master server
-func NewServer() *Server {
+// Config holds the tunable settings of the master server
+type Config struct {
+	MetadataDir          string          // Directory for the operation log and checkpoints
+	ChunkSize            int64           // Maximum chunk size in bytes; defaults to DefaultChunkSize
+	DeletedFileRetention time.Duration   // Grace period before deleted files are reclaimed; defaults to DefaultDeletedFileRetention
+	Placement            PlacementPolicy // Where replicas go; defaults to SpreadPlacement
+	HighWaterMark        float64         // Disk utilization (0-1] at which chunkservers get no new chunks; defaults to DefaultHighWaterMark
+	StartBalancer        bool            // Run the balancer from startup instead of waiting for SetBalancer
+	BalancerThreshold    float64         // Utilization distance from the average that triggers moves; defaults to DefaultBalancerThreshold
+	BalancerBandwidth    int64           // Bytes per second the balancer may move; defaults to DefaultBalancerBandwidth
+}
+
+// DefaultChunkSize is the chunk size used when Config.ChunkSize is unset
+const DefaultChunkSize = 64 << 20
+
+// DefaultHighWaterMark is the disk utilization used when Config.HighWaterMark is unset
+const DefaultHighWaterMark = 0.9
+
+// checkpointInterval is how often the operation log is compacted into a checkpoint
+const checkpointInterval = 5 * time.Minute
+
+// chunkHandleBatch is how many chunk handles one reservation record covers
+const chunkHandleBatch = 1024
+
+// initialChunkVersion is the version of a newly written chunk
+const initialChunkVersion = 1
+
+// defaultReplicationFactor is the number of replicas kept of every chunk
+const defaultReplicationFactor = 3
+
+// NewServer creates a new master server, recovering metadata from cfg.MetadataDir
+func NewServer(cfg Config) *Server {
+	if cfg.ChunkSize <= 0 {
+		cfg.ChunkSize = DefaultChunkSize
+	}
+	if cfg.DeletedFileRetention <= 0 {
+		cfg.DeletedFileRetention = DefaultDeletedFileRetention
+	}
+	if cfg.Placement == nil {
+		cfg.Placement = SpreadPlacement{}
+	}
+	if cfg.HighWaterMark <= 0 || cfg.HighWaterMark > 1 {
+		cfg.HighWaterMark = DefaultHighWaterMark
+	}
+	if cfg.BalancerThreshold <= 0 {
+		cfg.BalancerThreshold = DefaultBalancerThreshold
+	}
+	if cfg.BalancerBandwidth <= 0 {
+		cfg.BalancerBandwidth = DefaultBalancerBandwidth
+	}
+
+	oplog, err := openOpLog(cfg.MetadataDir)
+	if err != nil {
+		log.Fatalf("Failed to open operation log: %v", err)
+	}
+
 	server := &Server{
-		fileMetadata:   make(map[string]*FileMetadata),
-		chunkLocations: make(map[string][]string),
-		chunkservers:   make(map[string]*ChunkserverInfo),
+		fileMetadata:         make(map[string]*FileMetadata),
+		directories:          make(map[string]bool),
+		deletedFiles:         make(map[string]*deletedFile),
+		chunkLocations:       make(map[string][]string),
+		chunkVersions:        make(map[string]uint64),
+		pendingChunks:        make(map[string]*pendingChunk),
+		chunkSizes:           make(map[string]int64),
+		chunkservers:         make(map[string]*ChunkserverInfo),
+		draining:             make(map[string]bool),
+		chunkserverConns:     make(map[string]*grpc.ClientConn),
+		replication:          newReplicationState(),
+		balancer:             newBalancerState(cfg),
+		placement:            cfg.Placement,
+		highWaterMark:        cfg.HighWaterMark,
+		oplog:                oplog,
+		chunkSize:            cfg.ChunkSize,
+		deletedFileRetention: cfg.DeletedFileRetention,
+		startedAt:            time.Now(),
+	}
+
+	// Rebuild the namespace from the last checkpoint and the log
+	if err := server.recoverMetadata(); err != nil {
+		log.Fatalf("Failed to recover master metadata: %v", err)
 	}
 
 	// Start health monitoring
 	go server.monitorChunkserverHealth()
 
+	// Start periodic checkpointing
+	go server.checkpointPeriodically()
+
+	// Start re-replicating chunks that lost replicas
+	go server.replicatePeriodically()
+
+	// Start garbage collection of deleted files and abandoned uploads
+	go server.collectGarbagePeriodically()
+
+	// Start evening out disk utilization when the balancer runs
+	go server.balancePeriodically()
+
 	return server
 }
 
-// getChunkserverClient creates a connection to the chunkserver
-func (s *Server) getChunkserverClient(address string) (gfs.ChunkserverClient, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	conn, err := grpc.DialContext(ctx, address, grpc.WithInsecure()) // TODO: Replace with secure connection in production
+// recoverMetadata loads the latest checkpoint and replays the operation log on top of it
+func (s *Server) recoverMetadata() error {
+	cp, err := s.oplog.loadCheckpoint()
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if cp != nil {
+		if cp.FileMetadata != nil {
+			s.fileMetadata = cp.FileMetadata
+		}
+		for dir := range cp.Directories {
+			s.directories[dir] = true
+		}
+		if cp.DeletedFiles != nil {
+			s.deletedFiles = cp.DeletedFiles
+		}
+		if cp.ChunkLocations != nil {
+			s.chunkLocations = cp.ChunkLocations
+		}
+		if cp.Draining != nil {
+			s.draining = cp.Draining
+		}
+		if cp.ChunkVersions != nil {
+			s.chunkVersions = cp.ChunkVersions
+		}
+		s.chunkHandleLimit = cp.ChunkHandleLimit
 	}
-	return gfs.NewChunkserverClient(conn), nil
-}
 
-// Heartbeat handles chunkserver heartbeats
-func (s *Server) Heartbeat(ctx context.Context, req *gfs.HeartbeatRequest) (*gfs.HeartbeatResponse, error) {
-	chunkserverID := req.GetChunkserverId()
+	if err := s.oplog.replay(s.applyRecord); err != nil {
+		return err
+	}
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	// Handles reserved before the restart may have been handed out, so skip them all
+	s.nextChunkHandle = max(s.chunkHandleLimit, 1)
 
-	// Register or update chunkserver info
-	// For now, we'll assume the chunkserverID is the address
-	// In a real system, you'd have a mapping from ID to address
-	s.chunkservers[chunkserverID] = &ChunkserverInfo{
-		Address:   chunkserverID,
-		LastSeen:  time.Now().Unix(),
-		IsHealthy: true,
+	log.Printf("Recovered metadata for %d files (%d chunks) up to log record %d",
+		len(s.fileMetadata), len(s.chunkLocations), s.oplog.lastSeq)
+
+	// Compact whatever was replayed so the next start is fast
+	return s.checkpoint()
+}
+
+// applyRecord applies a logged mutation to the in-memory metadata
+func (s *Server) applyRecord(rec *logRecord) {
+	switch rec.Op {
+	case opUploadFile:
+		// Chunks of a file being overwritten are no longer referenced
+		if oldMeta, exists := s.fileMetadata[rec.Filename]; exists {
+			for _, chunkHandle := range oldMeta.ChunkHandles {
+				if _, reused := rec.ChunkLocations[chunkHandle]; !reused {
+					delete(s.chunkLocations, chunkHandle)
+					delete(s.chunkVersions, chunkHandle)
+					delete(s.chunkSizes, chunkHandle)
+				}
+			}
+		}
+		s.fileMetadata[rec.Filename] = rec.Metadata
+		s.addParents(rec.Filename)
+		for chunkHandle, locations := range rec.ChunkLocations {
+			s.chunkLocations[chunkHandle] = locations
+		}
+		for chunkHandle, version := range rec.ChunkVersions {
+			s.chunkVersions[chunkHandle] = version
+		}
+	case opHideFile:
+		s.hideFile(rec.Filename, rec.HiddenName, rec.DeletedAt)
+	case opDeleteDir:
+		for filename := range s.fileMetadata {
+			if isUnder(filename, rec.Path) {
+				s.hideFile(filename, hiddenName(filename, rec.DeletedAt), rec.DeletedAt)
+			}
+		}
+		for dir := range s.directories {
+			if isUnder(dir, rec.Path) {
+				delete(s.directories, dir)
+			}
+		}
+		delete(s.directories, rec.Path)
+	case opMkdir:
+		s.directories[rec.Path] = true
+		s.addParents(rec.Path)
+	case opRmdir:
+		delete(s.directories, rec.Path)
+	case opRename:
+		s.applyRename(rec.Path, rec.NewPath, rec.DeletedAt)
+	case opReclaimFiles:
+		for _, name := range rec.HiddenNames {
+			if file, exists := s.deletedFiles[name]; exists {
+				for _, chunkHandle := range file.Metadata.ChunkHandles {
+					delete(s.chunkLocations, chunkHandle)
+					delete(s.chunkVersions, chunkHandle)
+					delete(s.chunkSizes, chunkHandle)
+				}
+			}
+			delete(s.deletedFiles, name)
+		}
+	case opSetLocations:
+		for chunkHandle, locations := range rec.ChunkLocations {
+			s.chunkLocations[chunkHandle] = locations
+		}
+	case opSetVersions:
+		for chunkHandle, version := range rec.ChunkVersions {
+			s.chunkVersions[chunkHandle] = version
+		}
+	case opSetDraining:
+		if rec.Drain {
+			s.draining[rec.ChunkserverID] = true
+		} else {
+			delete(s.draining, rec.ChunkserverID)
+		}
+	case opSetReplication:
+		if fileMeta, exists := s.fileMetadata[rec.Filename]; exists {
+			fileMeta.ReplicationFactor = rec.ReplicationFactor
+		}
+	case opReserveHandles:
+		if rec.HandleLimit > s.chunkHandleLimit {
+			s.chunkHandleLimit = rec.HandleLimit
+		}
+	default:
+		log.Printf("Skipping unknown operation log record %d of type %q", rec.Seq, rec.Op)
 	}
+}
 
-	log.Printf("Received heartbeat from: %s", chunkserverID)
+// logAndApply durably records a mutation and then applies it; callers must hold s.mu
+func (s *Server) logAndApply(rec *logRecord) error {
+	if err := s.oplog.append(rec); err != nil {
+		return err
+	}
+	s.applyRecord(rec)
+	return nil
+}
 
-	return &gfs.HeartbeatResponse{Message: "Heartbeat received"}, nil
+// checkpointPeriodically compacts the operation log at a fixed interval
+func (s *Server) checkpointPeriodically() {
+	ticker := time.NewTicker(checkpointInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := s.checkpoint(); err != nil {
+			log.Printf("Failed to write checkpoint: %v", err)
+		}
+	}
 }
 
-// getAvailableChunkservers returns a list of healthy chunkservers
-func (s *Server) getAvailableChunkservers() []string {
+// checkpoint snapshots the metadata if anything was logged since the last one
+func (s *Server) checkpoint() error {
+	// Mutations hold the write lock, so a read lock gives a consistent snapshot
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	var available []string
+	if s.oplog.pending == 0 {
+		return nil
+	}
+
+	if err := s.oplog.checkpoint(&checkpoint{
+		FileMetadata:     s.fileMetadata,
+		Directories:      s.directories,
+		DeletedFiles:     s.deletedFiles,
+		ChunkLocations:   s.chunkLocations,
+		ChunkVersions:    s.chunkVersions,
+		ChunkHandleLimit: s.chunkHandleLimit,
+		Draining:         s.draining,
+	}); err != nil {
+		return err
+	}
+
+	log.Printf("Wrote checkpoint at log record %d", s.oplog.lastSeq)
+	return nil
+}
+
+// newFileMetadata builds the metadata recorded when filename is (over)written;
+// callers must hold s.mu
+func (s *Server) newFileMetadata(filename string, chunkHandles []string, size int64, checksum string, replicationFactor int) *FileMetadata {
 	now := time.Now().Unix()
 
-	for address, info := range s.chunkservers {
-		// Check if chunkserver is healthy and recently seen (within 30 seconds)
-		if info.IsHealthy && (now-info.LastSeen) < 30 {
-			available = append(available, address)
+	// Overwriting keeps the original creation time
+	createdAt := now
+	if oldMeta, exists := s.fileMetadata[filename]; exists && oldMeta.CreatedAt != 0 {
+		createdAt = oldMeta.CreatedAt
+	}
+
+	return &FileMetadata{
+		ChunkHandles:      chunkHandles,
+		Size:              size,
+		CreatedAt:         createdAt,
+		ModifiedAt:        now,
+		ReplicationFactor: replicationFactor,
+		Checksum:          checksum,
+	}
+}
+
+// newChunkHandle allocates a globally unique, opaque chunk handle; callers must hold s.mu
+func (s *Server) newChunkHandle() (string, error) {
+	if s.nextChunkHandle >= s.chunkHandleLimit {
+		// Reserve the next batch durably so a restarted master never reuses a handle
+		err := s.logAndApply(&logRecord{
+			Op:          opReserveHandles,
+			HandleLimit: s.nextChunkHandle + chunkHandleBatch,
+		})
+		if err != nil {
+			return "", err
 		}
 	}
 
-	return available
+	handle := s.nextChunkHandle
+	s.nextChunkHandle++
+	return gfs.FormatChunkHandle(handle), nil
 }
 
-// UploadFile handles file uploads with replication
-func (s *Server) UploadFile(ctx context.Context, req *gfs.UploadFileRequest) (*gfs.UploadFileResponse, error) {
-	filename := req.GetFilename()
-	data := req.GetData()
+// chunkserverAddresses resolves chunkserver IDs to their current addresses,
+// skipping chunkservers that have not registered; callers must hold s.mu
+func (s *Server) chunkserverAddresses(chunkserverIDs []string) []string {
+	addresses := make([]string, 0, len(chunkserverIDs))
+	for _, id := range chunkserverIDs {
+		if info, exists := s.chunkservers[id]; exists {
+			addresses = append(addresses, info.Address)
+		}
+	}
+	return addresses
+}
+
+// getChunkserverClient returns a (cached) client for the chunkserver with the given ID
+func (s *Server) getChunkserverClient(chunkserverID string) (gfs.ChunkserverClient, error) {
+	s.mu.RLock()
+	info, exists := s.chunkservers[chunkserverID]
+	var address string
+	if exists {
+		address = info.Address
+	}
+	s.mu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("chunkserver %s is not registered", chunkserverID)
+	}
+
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+
+	conn, exists := s.chunkserverConns[address]
+	if !exists {
+		var err error
+		conn, err = grpc.Dial(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, fmt.Errorf("connect to chunkserver %s: %w", address, err)
+		}
+		s.chunkserverConns[address] = conn
+	}
+	return gfs.NewChunkserverClient(conn), nil
+}
 
-	// Generate a chunk handle for this file
-	chunkHandle := fmt.Sprintf("%s-0", filename)
+// closeChunkserverConn closes the cached connection to a chunkserver address
+// that is no longer used
+func (s *Server) closeChunkserverConn(address string) {
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+
+	if conn, exists := s.chunkserverConns[address]; exists {
+		conn.Close()
+		delete(s.chunkserverConns, address)
+	}
+}
 
-	// Get available chunkservers (needs to be called before acquiring lock to avoid deadlock)
-	availableChunkservers := s.getAvailableChunkservers()
+// Heartbeat handles chunkserver heartbeats. The response carries the commands queued
+// for the chunkserver, which acknowledges them with its next heartbeat.
+func (s *Server) Heartbeat(ctx context.Context, req *gfs.HeartbeatRequest) (*gfs.HeartbeatResponse, error) {
+	chunkserverID := req.GetChunkserverId()
+	now := time.Now()
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// Check if we have any chunkservers available
-	if len(availableChunkservers) == 0 {
-		return &gfs.UploadFileResponse{
-			Success: false,
-			Message: "No chunkservers available",
-		}, nil
+	// Register or update chunkserver info
+	info := s.touchChunkserver(chunkserverID, req.GetAddress(), req.GetTopology())
+	if req.GetStats() != nil {
+		info.Stats = req.GetStats()
+		info.PlacedBytes = 0
 	}
 
-	// Replicate chunk to multiple chunkservers (3 replicas)
-	replicaCount := 3
-	if len(availableChunkservers) < replicaCount {
-		replicaCount = len(availableChunkservers)
+	log.Printf("Received heartbeat from: %s", chunkserverID)
+
+	s.handleCommandResults(chunkserverID, info, req.GetCommandResults(), now)
+	s.dropCorruptReplicas(chunkserverID, req.GetCorruptChunks())
+
+	// A restarted master has no locations from this chunkserver until it reports
+	if info.LastReport == 0 && !info.awaitingCommand(gfs.ChunkserverCommand_REPORT) {
+		s.queueCommand(chunkserverID, &gfs.ChunkserverCommand{Type: gfs.ChunkserverCommand_REPORT})
 	}
 
-	var successfulReplicas []string
+	// A restarted chunkserver forgets that it is being drained
+	s.syncDrainMode(chunkserverID, info)
 
-	for i := 0; i < replicaCount; i++ {
-		chunkserverAddr := availableChunkservers[i]
+	return &gfs.HeartbeatResponse{
+		Message:  "Heartbeat received",
+		Commands: s.takeCommands(info, now),
+	}, nil
+}
 
-		// Get chunkserver client
-		chunkserverClient, err := s.getChunkserverClient(chunkserverAddr)
-		if err != nil {
-			log.Printf("Failed to connect to chunkserver %s: %v", chunkserverAddr, err)
-			continue
-		}
+// ListChunkservers reports every known chunkserver with its health, storage and load
+func (s *Server) ListChunkservers(ctx context.Context, req *gfs.ListChunkserversRequest) (*gfs.ListChunkserversResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-		// Store the chunk on chunkserver
-		storeReq := &gfs.StoreChunkRequest{
-			ChunkHandle: chunkHandle,
-			Data:        data,
+	now := time.Now().Unix()
+	chunkservers := make([]*gfs.ChunkserverStatus, 0, len(s.chunkservers))
+	for id, info := range s.chunkservers {
+		status := &gfs.ChunkserverStatus{
+			Id:       id,
+			Address:  info.Address,
+			LastSeen: info.LastSeen,
+			Healthy:  info.available(now),
+			Stats:    info.Stats,
+			Topology: info.Topology,
+			Full:     info.full(s.highWaterMark, s.chunkSize),
+			Draining: s.draining[id],
 		}
-
-		_, err = chunkserverClient.StoreChunk(ctx, storeReq)
-		if err != nil {
-			log.Printf("Failed to store chunk %s on %s: %v", chunkHandle, chunkserverAddr, err)
-			continue
+		if status.Draining {
+			status.ChunksToDrain = int32(s.chunksToDrain(id))
+			status.Drained = status.ChunksToDrain == 0
 		}
+		chunkservers = append(chunkservers, status)
+	}
+	sort.Slice(chunkservers, func(i, j int) bool {
+		return chunkservers[i].GetAddress() < chunkservers[j].GetAddress()
+	})
+
+	return &gfs.ListChunkserversResponse{
+		Success:      true,
+		Message:      fmt.Sprintf("Found %d chunkservers", len(chunkservers)),
+		Chunkservers: chunkservers,
+		Replication:  s.replicationStatus(),
+		Balancer:     s.balancerStatus(),
+	}, nil
+}
+
+// available reports whether the chunkserver is healthy and was seen recently (within 30 seconds)
+func (info *ChunkserverInfo) available(now int64) bool {
+	return info.IsHealthy && (now-info.LastSeen) < 30
+}
 
-		successfulReplicas = append(successfulReplicas, chunkserverAddr)
-		log.Printf("Stored chunk %s on %s", chunkHandle, chunkserverAddr)
+// getAvailableChunkservers returns the IDs of healthy chunkservers
+func (s *Server) getAvailableChunkservers() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var available []string
+	now := time.Now().Unix()
+
+	for id, info := range s.chunkservers {
+		if info.available(now) {
+			available = append(available, id)
+		}
 	}
 
-	if len(successfulReplicas) == 0 {
+	return available
+}
+
+// UploadFile handles file uploads with replication
+func (s *Server) UploadFile(ctx context.Context, req *gfs.UploadFileRequest) (*gfs.UploadFileResponse, error) {
+	upload, err := s.newFileUpload(ctx, req.GetFilename(), req.GetReplicationFactor())
+	if err != nil {
 		return &gfs.UploadFileResponse{
 			Success: false,
-			Message: "Failed to store chunk on any chunkserver",
+			Message: fmt.Sprintf("Upload failed: %v", err),
 		}, nil
 	}
+	defer upload.close()
 
-	// Update metadata
-	s.fileMetadata[filename] = &FileMetadata{
-		ChunkHandles: []string{chunkHandle},
-		Size:         int64(len(data)),
+	// Split the file into fixed-size chunks and replicate each one
+	if _, err := upload.Write(req.GetData()); err != nil {
+		return upload.response(err), nil
 	}
 
-	// Record chunk locations
-	s.chunkLocations[chunkHandle] = successfulReplicas
-
-	log.Printf("Uploaded file %s (%d bytes) as chunk %s with %d replicas",
-		filename, len(data), chunkHandle, len(successfulReplicas))
-
-	return &gfs.UploadFileResponse{
-		Success: true,
-		Message: fmt.Sprintf("File uploaded successfully with %d replicas", len(successfulReplicas)),
-	}, nil
+	return upload.response(upload.commit()), nil
 }
 
 // DownloadFile handles file downloads
 func (s *Server) DownloadFile(ctx context.Context, req *gfs.DownloadFileRequest) (*gfs.DownloadFileResponse, error) {
-	filename := req.GetFilename()
+	// An invalid name matches no file
+	filename, _ := normalizePath(req.GetFilename())
 
 	s.mu.RLock()
 	fileMeta, exists := s.fileMetadata[filename]
@@ -199,90 +600,56 @@ func (s *Server) DownloadFile(ctx context.Context, req *gfs.DownloadFileRequest)
 		}, nil
 	}
 
-	// For now, we only support single-chunk files
-	if len(fileMeta.ChunkHandles) != 1 {
+	// Reassemble the file from its chunks in order
+	var data bytes.Buffer
+	data.Grow(int(fileMeta.Size))
+	for i, chunkHandle := range fileMeta.ChunkHandles {
+		if _, err := s.readChunk(ctx, chunkHandle, &data); err != nil {
+			log.Printf("Failed to download chunk %d of %s: %v", i, filename, err)
+			return &gfs.DownloadFileResponse{
+				Success: false,
+				Data:    nil,
+				Message: fmt.Sprintf("Failed to retrieve chunk %d from any chunkserver", i),
+			}, nil
+		}
+	}
+
+	// Chunks that all ended cleanly can still add up short if a replica lost data
+	if int64(data.Len()) != fileMeta.Size {
+		log.Printf("Downloaded %d bytes of %s, expected %d", data.Len(), filename, fileMeta.Size)
 		return &gfs.DownloadFileResponse{
 			Success: false,
 			Data:    nil,
-			Message: "Multi-chunk files not supported yet",
+			Message: fmt.Sprintf("Read %d bytes of %d", data.Len(), fileMeta.Size),
 		}, nil
 	}
 
-	chunkHandle := fileMeta.ChunkHandles[0]
-
-	// Get chunk locations
-	s.mu.RLock()
-	locations := s.chunkLocations[chunkHandle]
-	s.mu.RUnlock()
-
-	// Try to retrieve from any available replica
-	for _, chunkserverAddr := range locations {
-		chunkserverClient, err := s.getChunkserverClient(chunkserverAddr)
-		if err != nil {
-			log.Printf("Failed to connect to chunkserver %s: %v", chunkserverAddr, err)
-			continue
-		}
-
-		retrieveReq := &gfs.RetrieveChunkRequest{
-			ChunkHandle: chunkHandle,
-		}
-
-		resp, err := chunkserverClient.RetrieveChunk(ctx, retrieveReq)
-		if err != nil {
-			log.Printf("Failed to retrieve chunk %s from %s: %v", chunkHandle, chunkserverAddr, err)
-			continue
-		}
-
-		if !resp.GetSuccess() {
-			log.Printf("Chunkserver %s returned error for chunk %s: %s", chunkserverAddr, chunkHandle, resp.GetMessage())
-			continue
-		}
-
-		log.Printf("Downloaded file %s (%d bytes) from %s", filename, len(resp.GetData()), chunkserverAddr)
-
-		return &gfs.DownloadFileResponse{
-			Success: true,
-			Data:    resp.GetData(),
-			Message: "File downloaded successfully",
-		}, nil
-	}
+	log.Printf("Downloaded file %s (%d bytes, %d chunks)", filename, data.Len(), len(fileMeta.ChunkHandles))
 
 	return &gfs.DownloadFileResponse{
-		Success: false,
-		Data:    nil,
-		Message: "Failed to retrieve chunk from any chunkserver",
-	}, nil
-}
-
-// ListFiles lists files in a directory
-func (s *Server) ListFiles(ctx context.Context, req *gfs.ListFilesRequest) (*gfs.ListFilesResponse, error) {
-	path := req.GetPath()
-
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	var files []string
-	for filename := range s.fileMetadata {
-		// Simple prefix matching for now
-		if path == "" || len(filename) >= len(path) && filename[:len(path)] == path {
-			files = append(files, filename)
-		}
-	}
-
-	return &gfs.ListFilesResponse{
 		Success: true,
-		Files:   files,
-		Message: fmt.Sprintf("Found %d files", len(files)),
+		Data:    data.Bytes(),
+		Message: "File downloaded successfully",
 	}, nil
 }
 
-// DeleteFile handles file deletion
+// DeleteFile handles file deletion, and recursive deletion of directories
 func (s *Server) DeleteFile(ctx context.Context, req *gfs.DeleteFileRequest) (*gfs.DeleteFileResponse, error) {
-	filename := req.GetFilename()
+	filename, err := normalizePath(req.GetFilename())
+	if err != nil {
+		return &gfs.DeleteFileResponse{
+			Success: false,
+			Message: fmt.Sprintf("Invalid path: %v", err),
+		}, nil
+	}
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if s.isDir(filename) {
+		return s.deleteDir(filename, req.GetRecursive()), nil
+	}
+
 	fileMeta, exists := s.fileMetadata[filename]
 	if !exists {
 		return &gfs.DeleteFileResponse{
@@ -291,45 +658,71 @@ func (s *Server) DeleteFile(ctx context.Context, req *gfs.DeleteFileRequest) (*g
 		}, nil
 	}
 
-	// Delete chunks from all replicas
-	for _, chunkHandle := range fileMeta.ChunkHandles {
-		locations := s.chunkLocations[chunkHandle]
+	// Hide the file; its chunks are reclaimed by garbage collection after the retention period
+	deletedAt := time.Now().UnixNano()
+	err = s.logAndApply(&logRecord{
+		Op:         opHideFile,
+		Filename:   filename,
+		HiddenName: hiddenName(filename, deletedAt),
+		DeletedAt:  deletedAt,
+	})
+	if err != nil {
+		log.Printf("Failed to log deletion of %s: %v", filename, err)
+		return &gfs.DeleteFileResponse{
+			Success: false,
+			Message: "Failed to persist file deletion",
+		}, nil
+	}
 
-		for _, chunkserverAddr := range locations {
-			chunkserverClient, err := s.getChunkserverClient(chunkserverAddr)
-			if err != nil {
-				log.Printf("Failed to connect to chunkserver %s: %v", chunkserverAddr, err)
-				continue
-			}
+	log.Printf("Deleted file %s (%d chunks kept for %s)", filename, len(fileMeta.ChunkHandles), s.deletedFileRetention)
 
-			deleteReq := &gfs.DeleteChunkRequest{
-				ChunkHandle: chunkHandle,
-			}
+	return &gfs.DeleteFileResponse{
+		Success: true,
+		Message: "File deleted successfully",
+	}, nil
+}
 
-			_, err = chunkserverClient.DeleteChunk(ctx, deleteReq)
-			if err != nil {
-				log.Printf("Failed to delete chunk %s from %s: %v", chunkHandle, chunkserverAddr, err)
-			}
+// deleteDir hides every file below dir and removes the directory tree in one
+// logged step; callers must hold s.mu
+func (s *Server) deleteDir(dir string, recursive bool) *gfs.DeleteFileResponse {
+	if dir == rootDir {
+		return &gfs.DeleteFileResponse{
+			Success: false,
+			Message: "The root directory cannot be deleted",
+		}
+	}
+	if !recursive {
+		return &gfs.DeleteFileResponse{
+			Success: false,
+			Message: fmt.Sprintf("%s is a directory; set recursive to delete it", dir),
 		}
-
-		// Remove from chunk locations
-		delete(s.chunkLocations, chunkHandle)
 	}
 
-	// Remove file metadata
-	delete(s.fileMetadata, filename)
+	err := s.logAndApply(&logRecord{
+		Op:        opDeleteDir,
+		Path:      dir,
+		DeletedAt: time.Now().UnixNano(),
+	})
+	if err != nil {
+		log.Printf("Failed to log deletion of directory %s: %v", dir, err)
+		return &gfs.DeleteFileResponse{
+			Success: false,
+			Message: "Failed to persist directory deletion",
+		}
+	}
 
-	log.Printf("Deleted file %s", filename)
+	log.Printf("Deleted directory %s recursively", dir)
 
 	return &gfs.DeleteFileResponse{
 		Success: true,
-		Message: "File deleted successfully",
-	}, nil
+		Message: "Directory deleted successfully",
+	}
 }
 
 // GetChunkLocations returns chunk locations for a file
 func (s *Server) GetChunkLocations(ctx context.Context, req *gfs.GetChunkLocationsRequest) (*gfs.GetChunkLocationsResponse, error) {
-	filename := req.GetFilename()
+	// An invalid name matches no file
+	filename, _ := normalizePath(req.GetFilename())
 	chunkIndex := req.GetChunkIndex()
 
 	s.mu.RLock()
@@ -347,15 +740,20 @@ func (s *Server) GetChunkLocations(ctx context.Context, req *gfs.GetChunkLocatio
 		return &gfs.GetChunkLocationsResponse{
 			ChunkserverAddresses: nil,
 			ChunkHandle:          "",
+			ChunkCount:           int32(len(fileMeta.ChunkHandles)),
+			FileSize:             fileMeta.Size,
 		}, nil
 	}
 
 	chunkHandle := fileMeta.ChunkHandles[chunkIndex]
-	locations := s.chunkLocations[chunkHandle]
+	locations := s.chunkserverAddresses(s.chunkLocations[chunkHandle])
 
 	return &gfs.GetChunkLocationsResponse{
 		ChunkserverAddresses: locations,
 		ChunkHandle:          chunkHandle,
+		ChunkCount:           int32(len(fileMeta.ChunkHandles)),
+		FileSize:             fileMeta.Size,
+		Version:              s.chunkVersions[chunkHandle],
 	}, nil
 }
 
@@ -375,116 +773,65 @@ func (s *Server) checkAndHandleFailedChunkservers() {
 	defer s.mu.Unlock()
 
 	now := time.Now().Unix()
-	var failedChunkservers []string
+	var failedChunkservers, removedChunkservers []string
 
 	// Identify failed chunkservers (no heartbeat for 60 seconds)
-	for address, info := range s.chunkservers {
+	for id, info := range s.chunkservers {
 		if (now - info.LastSeen) > 60 {
+			// A drained chunkserver that goes away was removed on purpose
+			if info.IsHealthy && s.draining[id] && s.chunksToDrain(id) == 0 {
+				removedChunkservers = append(removedChunkservers, id)
+			}
 			info.IsHealthy = false
-			failedChunkservers = append(failedChunkservers, address)
-			log.Printf("Chunkserver %s marked as failed (last seen: %d seconds ago)", address, now-info.LastSeen)
+			failedChunkservers = append(failedChunkservers, id)
+			log.Printf("Chunkserver %s marked as failed (last seen: %d seconds ago)", id, now-info.LastSeen)
 		}
 	}
 
-	// Handle re-replication for chunks on failed chunkservers
-	for _, failedAddr := range failedChunkservers {
-		s.handleChunkserverFailure(failedAddr)
+	// Drop the replicas held by failed chunkservers
+	for _, failedID := range failedChunkservers {
+		s.handleChunkserverFailure(failedID)
 	}
-}
-
-// handleChunkserverFailure handles re-replication when a chunkserver fails
-func (s *Server) handleChunkserverFailure(failedAddr string) {
-	log.Printf("Handling failure of chunkserver %s", failedAddr)
-
-	// Find chunks that were stored on the failed chunkserver
-	for chunkHandle, locations := range s.chunkLocations {
-		// Check if this chunk was stored on the failed chunkserver
-		hasFailedReplica := false
-		for _, addr := range locations {
-			if addr == failedAddr {
-				hasFailedReplica = true
-				break
-			}
-		}
-
-		if hasFailedReplica {
-			// Remove failed chunkserver from locations
-			var newLocations []string
-			for _, addr := range locations {
-				if addr != failedAddr {
-					newLocations = append(newLocations, addr)
-				}
-			}
-			s.chunkLocations[chunkHandle] = newLocations
-
-			// Try to re-replicate if we have at least one good replica
-			if len(newLocations) > 0 {
-				s.replicateChunk(chunkHandle, newLocations[0])
-			}
-		}
+	for _, id := range removedChunkservers {
+		s.forgetChunkserver(id)
 	}
-}
 
-// replicateChunk replicates a chunk from a source chunkserver to available chunkservers
-func (s *Server) replicateChunk(chunkHandle, sourceAddr string) {
-	// Get available chunkservers (excluding the source)
-	availableChunkservers := s.getAvailableChunkservers()
-	var targetChunkservers []string
+	// Locations recovered from the log are only hints until chunkservers confirm them
+	if !s.locationsPruned && time.Since(s.startedAt) > reportGracePeriod {
+		s.pruneUnconfirmedLocations()
+		s.locationsPruned = true
 
-	for _, addr := range availableChunkservers {
-		if addr != sourceAddr {
-			targetChunkservers = append(targetChunkservers, addr)
+		// Chunkservers drained before the restart that never came back were removed
+		for id := range s.draining {
+			if _, known := s.chunkservers[id]; !known {
+				s.forgetChunkserver(id)
+			}
 		}
 	}
 
-	if len(targetChunkservers) == 0 {
-		log.Printf("No available chunkservers for re-replication of chunk %s", chunkHandle)
-		return
-	}
-
-	// Get the chunk data from the source
-	sourceClient, err := s.getChunkserverClient(sourceAddr)
-	if err != nil {
-		log.Printf("Failed to connect to source chunkserver %s: %v", sourceAddr, err)
-		return
-	}
-
-	ctx := context.Background()
-	retrieveReq := &gfs.RetrieveChunkRequest{ChunkHandle: chunkHandle}
-	resp, err := sourceClient.RetrieveChunk(ctx, retrieveReq)
-	if err != nil || !resp.GetSuccess() {
-		log.Printf("Failed to retrieve chunk %s from source %s: %v", chunkHandle, sourceAddr, err)
-		return
-	}
+	// Queue chunks below their replication goal for the scheduler
+	s.checkReplication()
+}
 
-	// Replicate to target chunkservers
-	var successfulReplicas []string
-	for _, targetAddr := range targetChunkservers {
-		targetClient, err := s.getChunkserverClient(targetAddr)
-		if err != nil {
-			log.Printf("Failed to connect to target chunkserver %s: %v", targetAddr, err)
-			continue
-		}
+// handleChunkserverFailure forgets the replicas held by a failed chunkserver; the
+// replication scheduler then restores the missing copies. Like chunk reports, this
+// is not written to the operation log: logged locations only serve as hints after
+// a restart, and those of a chunkserver that never reports again are pruned.
+// Callers must hold s.mu.
+func (s *Server) handleChunkserverFailure(failedID string) {
+	log.Printf("Handling failure of chunkserver %s", failedID)
 
-		storeReq := &gfs.StoreChunkRequest{
-			ChunkHandle: chunkHandle,
-			Data:        resp.GetData(),
-		}
+	// Copies it was to make will not be acknowledged; free their slots
+	s.abandonCopies(failedID)
 
-		_, err = targetClient.StoreChunk(ctx, storeReq)
-		if err != nil {
-			log.Printf("Failed to store chunk %s on target %s: %v", chunkHandle, targetAddr, err)
-			continue
+	var dropped int
+	for chunkHandle, locations := range s.chunkLocations {
+		if containsString(locations, failedID) {
+			s.chunkLocations[chunkHandle] = removeString(locations, failedID)
+			dropped++
 		}
-
-		successfulReplicas = append(successfulReplicas, targetAddr)
-		log.Printf("Re-replicated chunk %s to %s", chunkHandle, targetAddr)
 	}
-
-	// Update chunk locations
-	if len(successfulReplicas) > 0 {
-		s.mu.Lock()
-		s.chunkLocations[chunkHandle] = append(s.chunkLocations[chunkHandle], successfulReplicas...)
-		s.mu.Unlock()
+	if dropped > 0 {
+		log.Printf("Dropped %d replicas held by failed chunkserver %s", dropped, failedID)
 	}
 }
@@ -0,0 +1,182 @@
+package master
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// newTestServer starts a master keeping its metadata in dir
+func newTestServer(t *testing.T, dir string) *Server {
+	t.Helper()
+	s := NewServer(Config{MetadataDir: dir})
+	t.Cleanup(func() { s.oplog.file.Close() })
+	return s
+}
+
+// metadataSnapshot is the durable part of the master metadata
+type metadataSnapshot struct {
+	FileMetadata     map[string]*FileMetadata
+	Directories      map[string]bool
+	DeletedFiles     map[string]*deletedFile
+	ChunkLocations   map[string][]string
+	ChunkVersions    map[string]uint64
+	Draining         map[string]bool
+	ChunkHandleLimit uint64
+}
+
+func snapshotMetadata(s *Server) metadataSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return metadataSnapshot{
+		FileMetadata:     s.fileMetadata,
+		Directories:      s.directories,
+		DeletedFiles:     s.deletedFiles,
+		ChunkLocations:   s.chunkLocations,
+		ChunkVersions:    s.chunkVersions,
+		Draining:         s.draining,
+		ChunkHandleLimit: s.chunkHandleLimit,
+	}
+}
+
+// recoveryRecords exercises every kind of record a running master logs
+func recoveryRecords() []*logRecord {
+	return []*logRecord{
+		{Op: opReserveHandles, HandleLimit: 1024},
+		{Op: opMkdir, Path: "/a/b"},
+		{
+			Op:       opUploadFile,
+			Filename: "/a/b/f",
+			Metadata: &FileMetadata{ChunkHandles: []string{"0000000000000001"}, Size: 10, CreatedAt: 1, ModifiedAt: 1},
+			ChunkLocations: map[string][]string{
+				"0000000000000001": {"cs-1", "cs-2"},
+			},
+			ChunkVersions: map[string]uint64{"0000000000000001": 1},
+		},
+		{
+			Op:             opUploadFile,
+			Filename:       "/g",
+			Metadata:       &FileMetadata{ChunkHandles: []string{"0000000000000002"}, Size: 5, CreatedAt: 2, ModifiedAt: 2},
+			ChunkLocations: map[string][]string{"0000000000000002": {"cs-2"}},
+			ChunkVersions:  map[string]uint64{"0000000000000002": 1},
+		},
+		{Op: opSetReplication, Filename: "/a/b/f", ReplicationFactor: 2},
+		{Op: opSetVersions, ChunkVersions: map[string]uint64{"0000000000000001": 2}},
+		{Op: opSetLocations, ChunkLocations: map[string][]string{"0000000000000001": {"cs-2", "cs-3"}}},
+		{Op: opRename, Path: "/a", NewPath: "/c", DeletedAt: 3},
+		{Op: opHideFile, Filename: "/g", HiddenName: hiddenName("/g", 4), DeletedAt: 4},
+		{Op: opSetDraining, ChunkserverID: "cs-1", Drain: true},
+		{Op: opMkdir, Path: "/d"},
+		{Op: opRmdir, Path: "/d"},
+	}
+}
+
+func TestRecoverMetadata(t *testing.T) {
+	records := recoveryRecords()
+	tests := []struct {
+		name            string
+		checkpointAfter int // records logged before a checkpoint; -1 for none
+		tail            string
+	}{
+		{"log only", -1, ""},
+		{"checkpoint only", len(records), ""},
+		{"checkpoint then log", 5, ""},
+		{"checkpoint then torn log", 5, `{"seq":99,"op":"rmdir","pa`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			s := newTestServer(t, dir)
+
+			for i, rec := range records {
+				if i == tt.checkpointAfter {
+					if err := s.checkpoint(); err != nil {
+						t.Fatalf("checkpoint: %v", err)
+					}
+				}
+				s.mu.Lock()
+				err := s.logAndApply(rec)
+				s.mu.Unlock()
+				if err != nil {
+					t.Fatalf("log record %d: %v", i, err)
+				}
+			}
+			if tt.checkpointAfter == len(records) {
+				if err := s.checkpoint(); err != nil {
+					t.Fatalf("checkpoint: %v", err)
+				}
+			}
+			want := snapshotMetadata(s)
+			s.oplog.file.Close()
+
+			if tt.tail != "" {
+				file, err := os.OpenFile(filepath.Join(dir, opLogFileName), os.O_WRONLY|os.O_APPEND, 0644)
+				if err != nil {
+					t.Fatal(err)
+				}
+				file.WriteString(tt.tail)
+				file.Close()
+			}
+
+			recovered := newTestServer(t, dir)
+			if got := snapshotMetadata(recovered); !reflect.DeepEqual(got, want) {
+				t.Fatalf("recovered metadata differs\ngot:  %+v\nwant: %+v", got, want)
+			}
+			if recovered.nextChunkHandle != 1024 {
+				t.Fatalf("next chunk handle is %d after recovery, want 1024", recovered.nextChunkHandle)
+			}
+		})
+	}
+}
+
+func TestChunkHandlesAcrossRestarts(t *testing.T) {
+	tests := []struct {
+		name       string
+		allocated  int
+		checkpoint bool
+	}{
+		{name: "none allocated", allocated: 0},
+		{name: "part of a batch", allocated: 3},
+		{name: "whole batch", allocated: chunkHandleBatch},
+		{name: "into the second batch", allocated: chunkHandleBatch + 1},
+		{name: "part of a batch, checkpointed", allocated: 3, checkpoint: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			s := newTestServer(t, dir)
+
+			seen := make(map[string]bool)
+			s.mu.Lock()
+			for range tt.allocated {
+				handle, err := s.newChunkHandle()
+				if err != nil || seen[handle] {
+					t.Fatalf("newChunkHandle() = %s, %v; want a new handle", handle, err)
+				}
+				seen[handle] = true
+			}
+			s.mu.Unlock()
+			if tt.checkpoint {
+				if err := s.checkpoint(); err != nil {
+					t.Fatalf("checkpoint: %v", err)
+				}
+			}
+			s.oplog.file.Close()
+
+			// Handles handed out before the restart are never handed out again
+			restarted := newTestServer(t, dir)
+			restarted.mu.Lock()
+			defer restarted.mu.Unlock()
+			for range chunkHandleBatch + 1 {
+				handle, err := restarted.newChunkHandle()
+				if err != nil || seen[handle] {
+					t.Fatalf("newChunkHandle() after restart = %s, %v; want a new handle", handle, err)
+				}
+				seen[handle] = true
+			}
+		})
+	}
+}
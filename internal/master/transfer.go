@@ -0,0 +1,362 @@
+package master
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"time"
+
+	"github.com/sdudhani/godfs/pkg/gfs"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// chunkUpload forwards the bytes of one chunk to all of its replicas as they arrive
+type chunkUpload struct {
+	*gfs.ChunkWriter
+	handle  string
+	version uint64
+}
+
+// openChunkUpload opens a write stream for chunkHandle on every target chunkserver
+func (s *Server) openChunkUpload(ctx context.Context, chunkHandle string, version uint64, targets []string) *chunkUpload {
+	return &chunkUpload{
+		ChunkWriter: gfs.OpenChunkWriter(ctx, chunkHandle, version, targets, s.getChunkserverClient),
+		handle:      chunkHandle,
+		version:     version,
+	}
+}
+
+// fileUpload cuts an incoming byte stream into fixed-size chunks and replicates each one
+type fileUpload struct {
+	s        *Server
+	ctx      context.Context
+	cancel   context.CancelFunc
+	filename string
+
+	replicaCount      int
+	replicationFactor int
+
+	current        *chunkUpload
+	chunkHandles   []string
+	chunkLocations map[string][]string
+	chunkVersions  map[string]uint64
+	minReplicas    int
+	size           int64
+	checksum       hash.Hash32
+}
+
+// newFileUpload prepares an upload of filename across the available chunkservers,
+// keeping the requested number of replicas of each chunk (0 for the default)
+func (s *Server) newFileUpload(ctx context.Context, filename string, requestedFactor int32) (*fileUpload, error) {
+	filename, err := normalizePath(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	err = s.checkFilePath(filename)
+	replicationFactor := defaultReplicationFactor
+	if err == nil {
+		replicationFactor, err = s.replicationFactor(filename, requestedFactor)
+	}
+	s.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	availableChunkservers := s.getAvailableChunkservers()
+
+	// Check if we have any chunkservers available
+	if len(availableChunkservers) == 0 {
+		return nil, errors.New("no chunkservers available")
+	}
+
+	// Replicate each chunk to multiple chunkservers; the scheduler adds any
+	// replicas that do not fit on the chunkservers available now
+	replicaCount := replicationFactor
+	if len(availableChunkservers) < replicaCount {
+		replicaCount = len(availableChunkservers)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	return &fileUpload{
+		s:                 s,
+		ctx:               ctx,
+		cancel:            cancel,
+		filename:          filename,
+		replicaCount:      replicaCount,
+		replicationFactor: replicationFactor,
+		chunkLocations:    make(map[string][]string),
+		chunkVersions:     make(map[string]uint64),
+		minReplicas:       replicaCount,
+		checksum:          gfs.NewChecksum(),
+	}, nil
+}
+
+// Write implements io.Writer, starting a new chunk every s.chunkSize bytes
+func (u *fileUpload) Write(p []byte) (int, error) {
+	written := 0
+
+	for len(p) > 0 {
+		if u.current == nil {
+			if err := u.startChunk(); err != nil {
+				return written, err
+			}
+		}
+
+		n := int(min(int64(len(p)), u.s.chunkSize-u.current.Size()))
+		if _, err := u.current.Write(p[:n]); err != nil {
+			return written, err
+		}
+		u.checksum.Write(p[:n])
+		written += n
+		u.size += int64(n)
+		p = p[n:]
+
+		if u.current.Size() == u.s.chunkSize {
+			if err := u.finishChunk(); err != nil {
+				return written, err
+			}
+		}
+	}
+
+	return written, nil
+}
+
+// startChunk opens write streams for the next chunk of the file
+func (u *fileUpload) startChunk() error {
+	index := len(u.chunkHandles)
+
+	// Allocate a fresh handle for this chunk; until the upload commits it is
+	// pending like a client allocation, so garbage collection leaves it alone
+	u.s.mu.Lock()
+	targets := u.s.placeReplicas(nil, u.replicaCount, time.Now().Unix())
+	var chunkHandle string
+	err := errors.New("no chunkserver has room for the chunk")
+	if len(targets) > 0 {
+		chunkHandle, err = u.s.newChunkHandle()
+		if err != nil {
+			log.Printf("Failed to allocate chunk handle for %s: %v", u.filename, err)
+			err = errors.New("failed to allocate chunk handle")
+		}
+	}
+	if err == nil {
+		u.s.pendingChunks[chunkHandle] = &pendingChunk{
+			Filename:    u.filename,
+			Index:       index,
+			Version:     initialChunkVersion,
+			Locations:   targets,
+			AllocatedAt: time.Now(),
+		}
+	}
+	u.s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	u.current = u.s.openChunkUpload(u.ctx, chunkHandle, initialChunkVersion, targets)
+	return nil
+}
+
+// finishChunk completes the current chunk and records where it was stored
+func (u *fileUpload) finishChunk() error {
+	index := len(u.chunkHandles)
+	chunk := u.current
+	u.current = nil
+	successfulReplicas := chunk.Close()
+
+	if len(successfulReplicas) == 0 {
+		return fmt.Errorf("failed to store chunk %d on any chunkserver", index)
+	}
+	for _, chunkserverID := range successfulReplicas {
+		log.Printf("Stored chunk %s (%d bytes) on %s", chunk.handle, chunk.Size(), chunkserverID)
+	}
+
+	if len(successfulReplicas) < u.minReplicas {
+		u.minReplicas = len(successfulReplicas)
+	}
+
+	u.chunkHandles = append(u.chunkHandles, chunk.handle)
+	u.chunkLocations[chunk.handle] = successfulReplicas
+	u.chunkVersions[chunk.handle] = chunk.version
+	return nil
+}
+
+// commit flushes the last chunk and durably records the file metadata
+func (u *fileUpload) commit() error {
+	// An empty file still gets one (empty) chunk
+	if u.current == nil && len(u.chunkHandles) == 0 {
+		if err := u.startChunk(); err != nil {
+			return err
+		}
+	}
+	if u.current != nil {
+		if err := u.finishChunk(); err != nil {
+			return err
+		}
+	}
+
+	u.s.mu.Lock()
+	defer u.s.mu.Unlock()
+
+	// The namespace may have changed while the data was written
+	if err := u.s.checkFilePath(u.filename); err != nil {
+		return err
+	}
+
+	// A client may have reallocated one of our chunks, or it expired meanwhile
+	for i, chunkHandle := range u.chunkHandles {
+		pending, exists := u.s.pendingChunks[chunkHandle]
+		if !exists || pending.Version != u.chunkVersions[chunkHandle] {
+			return fmt.Errorf("chunk %d was reallocated during the upload", i)
+		}
+	}
+
+	// Persist and apply the metadata and chunk locations
+	err := u.s.logAndApply(&logRecord{
+		Op:             opUploadFile,
+		Filename:       u.filename,
+		Metadata:       u.s.newFileMetadata(u.filename, u.chunkHandles, u.size, gfs.FormatChecksum(u.checksum.Sum32()), u.replicationFactor),
+		ChunkLocations: u.chunkLocations,
+		ChunkVersions:  u.chunkVersions,
+	})
+	if err != nil {
+		log.Printf("Failed to log upload of %s: %v", u.filename, err)
+		return errors.New("failed to persist file metadata")
+	}
+
+	for _, chunkHandle := range u.chunkHandles {
+		delete(u.s.pendingChunks, chunkHandle)
+	}
+
+	log.Printf("Uploaded file %s (%d bytes) as %d chunks with at least %d replicas",
+		u.filename, u.size, len(u.chunkHandles), u.minReplicas)
+	return nil
+}
+
+// close releases any write streams that are still open
+func (u *fileUpload) close() {
+	u.cancel()
+}
+
+// response builds the UploadFileResponse for a finished upload
+func (u *fileUpload) response(err error) *gfs.UploadFileResponse {
+	if err != nil {
+		return &gfs.UploadFileResponse{
+			Success: false,
+			Message: fmt.Sprintf("Upload failed: %v", err),
+		}
+	}
+
+	return &gfs.UploadFileResponse{
+		Success: true,
+		Message: fmt.Sprintf("File uploaded successfully as %d chunks with %d replicas", len(u.chunkHandles), u.minReplicas),
+	}
+}
+
+// readChunk streams a chunk into w, failing over between replicas and resuming
+// at the offset where the previous replica stopped, and returns the bytes written
+func (s *Server) readChunk(ctx context.Context, chunkHandle string, w io.Writer) (int64, error) {
+	s.mu.RLock()
+	locations := s.chunkLocations[chunkHandle]
+	version := s.chunkVersions[chunkHandle]
+	s.mu.RUnlock()
+
+	return gfs.ReadChunkReplicas(ctx, chunkHandle, version, locations, s.getChunkserverClient, w)
+}
+
+// UploadFileStream handles uploads sent as a stream of bounded frames
+func (s *Server) UploadFileStream(stream gfs.Master_UploadFileStreamServer) error {
+	// The first frame names the file
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return stream.SendAndClose(&gfs.UploadFileResponse{
+			Success: false,
+			Message: "Empty upload stream",
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	upload, err := s.newFileUpload(stream.Context(), first.GetFilename(), first.GetReplicationFactor())
+	if err != nil {
+		return stream.SendAndClose(&gfs.UploadFileResponse{
+			Success: false,
+			Message: fmt.Sprintf("Upload failed: %v", err),
+		})
+	}
+	defer upload.close()
+
+	for frame := first; ; {
+		if _, err := upload.Write(frame.GetData()); err != nil {
+			return stream.SendAndClose(upload.response(err))
+		}
+
+		frame, err = stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return stream.SendAndClose(upload.response(upload.commit()))
+}
+
+// downloadStreamWriter adapts a DownloadFileStream to io.Writer
+type downloadStreamWriter struct {
+	stream gfs.Master_DownloadFileStreamServer
+}
+
+// Write sends p to the client in bounded frames
+func (w downloadStreamWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := min(len(p), gfs.StreamFrameSize)
+		if err := w.stream.Send(&gfs.DownloadFileStreamResponse{Data: p[:n]}); err != nil {
+			return written, err
+		}
+		written += n
+		p = p[n:]
+	}
+	return written, nil
+}
+
+// DownloadFileStream streams a file back to the client chunk by chunk
+func (s *Server) DownloadFileStream(req *gfs.DownloadFileRequest, stream gfs.Master_DownloadFileStreamServer) error {
+	// An invalid name matches no file
+	filename, _ := normalizePath(req.GetFilename())
+
+	s.mu.RLock()
+	fileMeta, exists := s.fileMetadata[filename]
+	s.mu.RUnlock()
+
+	if !exists {
+		return status.Errorf(codes.NotFound, "file %s not found", filename)
+	}
+
+	var size int64
+	for i, chunkHandle := range fileMeta.ChunkHandles {
+		n, err := s.readChunk(stream.Context(), chunkHandle, downloadStreamWriter{stream: stream})
+		size += n
+		if err != nil {
+			log.Printf("Failed to stream chunk %d of %s: %v", i, filename, err)
+			return status.Errorf(codes.Unavailable, "failed to retrieve chunk %d: %v", i, err)
+		}
+	}
+
+	// Chunks that all ended cleanly can still add up short if a replica lost data
+	if size != fileMeta.Size {
+		log.Printf("Streamed %d bytes of %s, expected %d", size, filename, fileMeta.Size)
+		return status.Errorf(codes.DataLoss, "read %d bytes of %s, expected %d", size, filename, fileMeta.Size)
+	}
+
+	log.Printf("Downloaded file %s (%d bytes, %d chunks, streamed)", filename, fileMeta.Size, len(fileMeta.ChunkHandles))
+	return nil
+}
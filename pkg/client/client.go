@@ -0,0 +1,207 @@
+// Package client reads and writes DistriStore files. It asks the master only
+// for metadata and moves chunk data directly to and from the chunkservers.
+package client
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/sdudhani/godfs/pkg/gfs"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Client is a DistriStore client bound to one master
+type Client struct {
+	conn   *grpc.ClientConn
+	master gfs.MasterClient
+
+	mu           sync.Mutex
+	chunkservers map[string]*grpc.ClientConn // address -> connection
+}
+
+// New creates a client for the master at masterAddr
+func New(masterAddr string) (*Client, error) {
+	conn, err := grpc.Dial(masterAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("connect to master %s: %w", masterAddr, err)
+	}
+
+	return &Client{
+		conn:         conn,
+		master:       gfs.NewMasterClient(conn),
+		chunkservers: make(map[string]*grpc.ClientConn),
+	}, nil
+}
+
+// Master returns the raw master RPC client for metadata operations
+func (c *Client) Master() gfs.MasterClient {
+	return c.master
+}
+
+// Close closes the connections to the master and all chunkservers
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for address, conn := range c.chunkservers {
+		conn.Close()
+		delete(c.chunkservers, address)
+	}
+	return c.conn.Close()
+}
+
+// chunkserver returns a (cached) client for the chunkserver at address
+func (c *Client) chunkserver(address string) (gfs.ChunkserverClient, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	conn, exists := c.chunkservers[address]
+	if !exists {
+		var err error
+		conn, err = grpc.Dial(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, fmt.Errorf("connect to chunkserver %s: %w", address, err)
+		}
+		c.chunkservers[address] = conn
+	}
+	return gfs.NewChunkserverClient(conn), nil
+}
+
+// Upload stores everything read from r as filename. Chunks are allocated by
+// the master and written straight to their replicas; the file becomes
+// visible only once the master commits it.
+func (c *Client) Upload(ctx context.Context, filename string, r io.Reader) error {
+	return c.UploadWithReplication(ctx, filename, r, 0)
+}
+
+// UploadWithReplication is Upload with the number of replicas to keep of each
+// chunk; 0 keeps the factor of the file being overwritten, or the master's default.
+func (c *Client) UploadWithReplication(ctx context.Context, filename string, r io.Reader, replicationFactor int) error {
+	checksum := gfs.NewChecksum()
+	reader := bufio.NewReaderSize(io.TeeReader(r, checksum), gfs.StreamFrameSize)
+	var chunks []*gfs.CommittedChunk
+
+	for index := 0; ; index++ {
+		// Stop once the input is exhausted, but always write at least one chunk
+		if index > 0 {
+			if _, err := reader.Peek(1); err == io.EOF {
+				break
+			} else if err != nil {
+				return fmt.Errorf("read input: %w", err)
+			}
+		}
+
+		alloc, err := c.master.AllocateChunk(ctx, &gfs.AllocateChunkRequest{
+			Filename:          filename,
+			ChunkIndex:        int32(index),
+			ReplicationFactor: int32(replicationFactor),
+		})
+		if err != nil {
+			return fmt.Errorf("allocate chunk %d: %w", index, err)
+		}
+		if !alloc.GetSuccess() {
+			return fmt.Errorf("allocate chunk %d: %s", index, alloc.GetMessage())
+		}
+
+		chunk, err := c.writeChunk(ctx, alloc, io.LimitReader(reader, alloc.GetChunkSize()))
+		if err != nil {
+			return fmt.Errorf("write chunk %d: %w", index, err)
+		}
+		chunks = append(chunks, chunk)
+
+		// A short chunk is the last one
+		if chunk.GetSize() < alloc.GetChunkSize() {
+			break
+		}
+	}
+
+	resp, err := c.master.CommitFile(ctx, &gfs.CommitFileRequest{
+		Filename:          filename,
+		Chunks:            chunks,
+		Checksum:          gfs.FormatChecksum(checksum.Sum32()),
+		ReplicationFactor: int32(replicationFactor),
+	})
+	if err != nil {
+		return fmt.Errorf("commit %s: %w", filename, err)
+	}
+	if !resp.GetSuccess() {
+		return fmt.Errorf("commit %s: %s", filename, resp.GetMessage())
+	}
+	return nil
+}
+
+// writeChunk streams r to every replica chosen by the master and reports those that stored it
+func (c *Client) writeChunk(ctx context.Context, alloc *gfs.AllocateChunkResponse, r io.Reader) (*gfs.CommittedChunk, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	chunkHandle := alloc.GetChunkHandle()
+	w := gfs.OpenChunkWriter(ctx, chunkHandle, alloc.GetVersion(), alloc.GetChunkserverAddresses(), c.chunkserver)
+
+	buf := make([]byte, gfs.StreamFrameSize)
+	for {
+		n, readErr := io.ReadFull(r, buf)
+		if _, err := w.Write(buf[:n]); err != nil {
+			return nil, err
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return nil, fmt.Errorf("read input: %w", readErr)
+		}
+	}
+
+	chunk := &gfs.CommittedChunk{ChunkHandle: chunkHandle, Size: w.Size(), Version: alloc.GetVersion()}
+	chunk.ChunkserverAddresses = w.Close()
+	if len(chunk.ChunkserverAddresses) == 0 {
+		return nil, fmt.Errorf("failed to store chunk %s on any chunkserver", chunkHandle)
+	}
+	return chunk, nil
+}
+
+// ErrNotFound is returned by Download when the file does not exist
+var ErrNotFound = errors.New("file not found")
+
+// Download writes the contents of filename to w, reading each chunk directly
+// from one of its replicas
+func (c *Client) Download(ctx context.Context, filename string, w io.Writer) error {
+	var size, fileSize int64
+	for index, count := 0, 1; index < count; index++ {
+		locations, err := c.master.GetChunkLocations(ctx, &gfs.GetChunkLocationsRequest{
+			Filename:   filename,
+			ChunkIndex: int32(index),
+		})
+		if err != nil {
+			return fmt.Errorf("locate chunk %d: %w", index, err)
+		}
+		if locations.GetChunkCount() == 0 {
+			return ErrNotFound
+		}
+		count = int(locations.GetChunkCount())
+		fileSize = locations.GetFileSize()
+
+		n, err := c.readChunk(ctx, locations, w)
+		size += n
+		if err != nil {
+			return fmt.Errorf("read chunk %d: %w", index, err)
+		}
+	}
+
+	// Chunks that all ended cleanly can still add up short if a replica lost data
+	if size != fileSize {
+		return fmt.Errorf("read %d bytes of %s, expected %d", size, filename, fileSize)
+	}
+	return nil
+}
+
+// readChunk streams a chunk into w, failing over between replicas and resuming
+// at the offset where the previous replica stopped, and returns the bytes written
+func (c *Client) readChunk(ctx context.Context, locations *gfs.GetChunkLocationsResponse, w io.Writer) (int64, error) {
+	return gfs.ReadChunkReplicas(ctx, locations.GetChunkHandle(), locations.GetVersion(), locations.GetChunkserverAddresses(), c.chunkserver, w)
+}
@@ -0,0 +1,25 @@
+package gfs
+
+import (
+	"fmt"
+	"hash"
+	"hash/crc32"
+)
+
+// castagnoli is the CRC-32C polynomial table used for all DistriStore checksums
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// NewChecksum returns a hash computing the CRC-32C checksum of file contents
+func NewChecksum() hash.Hash32 {
+	return crc32.New(castagnoli)
+}
+
+// Checksum returns the CRC-32C checksum of data
+func Checksum(data []byte) uint32 {
+	return crc32.Checksum(data, castagnoli)
+}
+
+// FormatChecksum renders a CRC-32C checksum as 8 lowercase hexadecimal digits
+func FormatChecksum(sum uint32) string {
+	return fmt.Sprintf("%08x", sum)
+}
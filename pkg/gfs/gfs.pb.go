@@ -24,10 +24,70 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type ChunkserverCommand_Type int32
+
+const (
+	ChunkserverCommand_UNKNOWN ChunkserverCommand_Type = 0
+	// Delete chunk_handles; chunks the master no longer references are garbage
+	ChunkserverCommand_DELETE ChunkserverCommand_Type = 1
+	// Copy chunk_handle at version from the chunkserver at source_address
+	ChunkserverCommand_REPLICATE ChunkserverCommand_Type = 2
+	// Send a full chunk report, e.g. after a master restart
+	ChunkserverCommand_REPORT ChunkserverCommand_Type = 3
+	// Stop accepting new chunks (drain) or accept them again
+	ChunkserverCommand_DRAIN ChunkserverCommand_Type = 4
+)
+
+// Enum value maps for ChunkserverCommand_Type.
+var (
+	ChunkserverCommand_Type_name = map[int32]string{
+		0: "UNKNOWN",
+		1: "DELETE",
+		2: "REPLICATE",
+		3: "REPORT",
+		4: "DRAIN",
+	}
+	ChunkserverCommand_Type_value = map[string]int32{
+		"UNKNOWN":   0,
+		"DELETE":    1,
+		"REPLICATE": 2,
+		"REPORT":    3,
+		"DRAIN":     4,
+	}
+)
+
+func (x ChunkserverCommand_Type) Enum() *ChunkserverCommand_Type {
+	p := new(ChunkserverCommand_Type)
+	*p = x
+	return p
+}
+
+func (x ChunkserverCommand_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ChunkserverCommand_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_pkg_gfs_gfs_proto_enumTypes[0].Descriptor()
+}
+
+func (ChunkserverCommand_Type) Type() protoreflect.EnumType {
+	return &file_pkg_gfs_gfs_proto_enumTypes[0]
+}
+
+func (x ChunkserverCommand_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ChunkserverCommand_Type.Descriptor instead.
+func (ChunkserverCommand_Type) EnumDescriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{41, 0}
+}
+
 type StoreChunkRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ChunkHandle   string                 `protobuf:"bytes,1,opt,name=chunk_handle,json=chunkHandle,proto3" json:"chunk_handle,omitempty"`
 	Data          []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	Version       uint64                 `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -76,6 +136,13 @@ func (x *StoreChunkRequest) GetData() []byte {
 	return nil
 }
 
+func (x *StoreChunkRequest) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
 type StoreChunkResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
@@ -328,28 +395,30 @@ func (x *DeleteChunkResponse) GetMessage() string {
 	return ""
 }
 
-type UploadFileRequest struct {
+// Frames of a streamed chunk write; the first frame must carry the chunk handle
+type WriteChunkRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	ChunkHandle   string                 `protobuf:"bytes,1,opt,name=chunk_handle,json=chunkHandle,proto3" json:"chunk_handle,omitempty"`
 	Data          []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	Version       uint64                 `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UploadFileRequest) Reset() {
-	*x = UploadFileRequest{}
+func (x *WriteChunkRequest) Reset() {
+	*x = WriteChunkRequest{}
 	mi := &file_pkg_gfs_gfs_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UploadFileRequest) String() string {
+func (x *WriteChunkRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UploadFileRequest) ProtoMessage() {}
+func (*WriteChunkRequest) ProtoMessage() {}
 
-func (x *UploadFileRequest) ProtoReflect() protoreflect.Message {
+func (x *WriteChunkRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_pkg_gfs_gfs_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -361,47 +430,56 @@ func (x *UploadFileRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UploadFileRequest.ProtoReflect.Descriptor instead.
-func (*UploadFileRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use WriteChunkRequest.ProtoReflect.Descriptor instead.
+func (*WriteChunkRequest) Descriptor() ([]byte, []int) {
 	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *UploadFileRequest) GetFilename() string {
+func (x *WriteChunkRequest) GetChunkHandle() string {
 	if x != nil {
-		return x.Filename
+		return x.ChunkHandle
 	}
 	return ""
 }
 
-func (x *UploadFileRequest) GetData() []byte {
+func (x *WriteChunkRequest) GetData() []byte {
 	if x != nil {
 		return x.Data
 	}
 	return nil
 }
 
-type UploadFileResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+func (x *WriteChunkRequest) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type ReadChunkRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ChunkHandle string                 `protobuf:"bytes,1,opt,name=chunk_handle,json=chunkHandle,proto3" json:"chunk_handle,omitempty"`
+	Offset      int64                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	// When non-zero, the read fails unless the replica holds exactly this version
+	Version       uint64 `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UploadFileResponse) Reset() {
-	*x = UploadFileResponse{}
+func (x *ReadChunkRequest) Reset() {
+	*x = ReadChunkRequest{}
 	mi := &file_pkg_gfs_gfs_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UploadFileResponse) String() string {
+func (x *ReadChunkRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UploadFileResponse) ProtoMessage() {}
+func (*ReadChunkRequest) ProtoMessage() {}
 
-func (x *UploadFileResponse) ProtoReflect() protoreflect.Message {
+func (x *ReadChunkRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_pkg_gfs_gfs_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -413,46 +491,56 @@ func (x *UploadFileResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UploadFileResponse.ProtoReflect.Descriptor instead.
-func (*UploadFileResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use ReadChunkRequest.ProtoReflect.Descriptor instead.
+func (*ReadChunkRequest) Descriptor() ([]byte, []int) {
 	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *UploadFileResponse) GetSuccess() bool {
+func (x *ReadChunkRequest) GetChunkHandle() string {
 	if x != nil {
-		return x.Success
+		return x.ChunkHandle
 	}
-	return false
+	return ""
 }
 
-func (x *UploadFileResponse) GetMessage() string {
+func (x *ReadChunkRequest) GetOffset() int64 {
 	if x != nil {
-		return x.Message
+		return x.Offset
 	}
-	return ""
+	return 0
 }
 
-type DownloadFileRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+func (x *ReadChunkRequest) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type ReadChunkResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Data  []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	// Size of the whole chunk, announced on the first frame so readers can tell
+	// a complete chunk from a stream that ended early
+	ChunkSize     *int64 `protobuf:"varint,2,opt,name=chunk_size,json=chunkSize,proto3,oneof" json:"chunk_size,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DownloadFileRequest) Reset() {
-	*x = DownloadFileRequest{}
+func (x *ReadChunkResponse) Reset() {
+	*x = ReadChunkResponse{}
 	mi := &file_pkg_gfs_gfs_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DownloadFileRequest) String() string {
+func (x *ReadChunkResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DownloadFileRequest) ProtoMessage() {}
+func (*ReadChunkResponse) ProtoMessage() {}
 
-func (x *DownloadFileRequest) ProtoReflect() protoreflect.Message {
+func (x *ReadChunkResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_pkg_gfs_gfs_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -464,41 +552,50 @@ func (x *DownloadFileRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DownloadFileRequest.ProtoReflect.Descriptor instead.
-func (*DownloadFileRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use ReadChunkResponse.ProtoReflect.Descriptor instead.
+func (*ReadChunkResponse) Descriptor() ([]byte, []int) {
 	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *DownloadFileRequest) GetFilename() string {
+func (x *ReadChunkResponse) GetData() []byte {
 	if x != nil {
-		return x.Filename
+		return x.Data
 	}
-	return ""
+	return nil
 }
 
-type DownloadFileResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Data          []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
-	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *ReadChunkResponse) GetChunkSize() int64 {
+	if x != nil && x.ChunkSize != nil {
+		return *x.ChunkSize
+	}
+	return 0
 }
 
-func (x *DownloadFileResponse) Reset() {
-	*x = DownloadFileResponse{}
+type UploadFileRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Filename string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	Data     []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	// Replicas to keep of each chunk; 0 keeps the factor of the file being
+	// overwritten, or the default
+	ReplicationFactor int32 `protobuf:"varint,3,opt,name=replication_factor,json=replicationFactor,proto3" json:"replication_factor,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *UploadFileRequest) Reset() {
+	*x = UploadFileRequest{}
 	mi := &file_pkg_gfs_gfs_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DownloadFileResponse) String() string {
+func (x *UploadFileRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DownloadFileResponse) ProtoMessage() {}
+func (*UploadFileRequest) ProtoMessage() {}
 
-func (x *DownloadFileResponse) ProtoReflect() protoreflect.Message {
+func (x *UploadFileRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_pkg_gfs_gfs_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -510,53 +607,54 @@ func (x *DownloadFileResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DownloadFileResponse.ProtoReflect.Descriptor instead.
-func (*DownloadFileResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use UploadFileRequest.ProtoReflect.Descriptor instead.
+func (*UploadFileRequest) Descriptor() ([]byte, []int) {
 	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *DownloadFileResponse) GetSuccess() bool {
+func (x *UploadFileRequest) GetFilename() string {
 	if x != nil {
-		return x.Success
+		return x.Filename
 	}
-	return false
+	return ""
 }
 
-func (x *DownloadFileResponse) GetData() []byte {
+func (x *UploadFileRequest) GetData() []byte {
 	if x != nil {
 		return x.Data
 	}
 	return nil
 }
 
-func (x *DownloadFileResponse) GetMessage() string {
+func (x *UploadFileRequest) GetReplicationFactor() int32 {
 	if x != nil {
-		return x.Message
+		return x.ReplicationFactor
 	}
-	return ""
+	return 0
 }
 
-type ListFilesRequest struct {
+type UploadFileResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListFilesRequest) Reset() {
-	*x = ListFilesRequest{}
+func (x *UploadFileResponse) Reset() {
+	*x = UploadFileResponse{}
 	mi := &file_pkg_gfs_gfs_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListFilesRequest) String() string {
+func (x *UploadFileResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListFilesRequest) ProtoMessage() {}
+func (*UploadFileResponse) ProtoMessage() {}
 
-func (x *ListFilesRequest) ProtoReflect() protoreflect.Message {
+func (x *UploadFileResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_pkg_gfs_gfs_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -568,41 +666,46 @@ func (x *ListFilesRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListFilesRequest.ProtoReflect.Descriptor instead.
-func (*ListFilesRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use UploadFileResponse.ProtoReflect.Descriptor instead.
+func (*UploadFileResponse) Descriptor() ([]byte, []int) {
 	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *ListFilesRequest) GetPath() string {
+func (x *UploadFileResponse) GetSuccess() bool {
 	if x != nil {
-		return x.Path
+		return x.Success
+	}
+	return false
+}
+
+func (x *UploadFileResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
 	}
 	return ""
 }
 
-type ListFilesResponse struct {
+type DownloadFileRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Files         []string               `protobuf:"bytes,2,rep,name=files,proto3" json:"files,omitempty"`
-	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListFilesResponse) Reset() {
-	*x = ListFilesResponse{}
+func (x *DownloadFileRequest) Reset() {
+	*x = DownloadFileRequest{}
 	mi := &file_pkg_gfs_gfs_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListFilesResponse) String() string {
+func (x *DownloadFileRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListFilesResponse) ProtoMessage() {}
+func (*DownloadFileRequest) ProtoMessage() {}
 
-func (x *ListFilesResponse) ProtoReflect() protoreflect.Message {
+func (x *DownloadFileRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_pkg_gfs_gfs_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -614,53 +717,41 @@ func (x *ListFilesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListFilesResponse.ProtoReflect.Descriptor instead.
-func (*ListFilesResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use DownloadFileRequest.ProtoReflect.Descriptor instead.
+func (*DownloadFileRequest) Descriptor() ([]byte, []int) {
 	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *ListFilesResponse) GetSuccess() bool {
-	if x != nil {
-		return x.Success
-	}
-	return false
-}
-
-func (x *ListFilesResponse) GetFiles() []string {
-	if x != nil {
-		return x.Files
-	}
-	return nil
-}
-
-func (x *ListFilesResponse) GetMessage() string {
+func (x *DownloadFileRequest) GetFilename() string {
 	if x != nil {
-		return x.Message
+		return x.Filename
 	}
 	return ""
 }
 
-type DeleteFileRequest struct {
+type DownloadFileResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Data          []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteFileRequest) Reset() {
-	*x = DeleteFileRequest{}
+func (x *DownloadFileResponse) Reset() {
+	*x = DownloadFileResponse{}
 	mi := &file_pkg_gfs_gfs_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteFileRequest) String() string {
+func (x *DownloadFileResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteFileRequest) ProtoMessage() {}
+func (*DownloadFileResponse) ProtoMessage() {}
 
-func (x *DeleteFileRequest) ProtoReflect() protoreflect.Message {
+func (x *DownloadFileResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_pkg_gfs_gfs_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -672,40 +763,57 @@ func (x *DeleteFileRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteFileRequest.ProtoReflect.Descriptor instead.
-func (*DeleteFileRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use DownloadFileResponse.ProtoReflect.Descriptor instead.
+func (*DownloadFileResponse) Descriptor() ([]byte, []int) {
 	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *DeleteFileRequest) GetFilename() string {
+func (x *DownloadFileResponse) GetSuccess() bool {
 	if x != nil {
-		return x.Filename
+		return x.Success
+	}
+	return false
+}
+
+func (x *DownloadFileResponse) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *DownloadFileResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
 	}
 	return ""
 }
 
-type DeleteFileResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+// Frames of a streamed upload; the first frame must carry the filename
+type UploadFileStreamRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Filename string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	Data     []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	// Read from the first frame only, like filename
+	ReplicationFactor int32 `protobuf:"varint,3,opt,name=replication_factor,json=replicationFactor,proto3" json:"replication_factor,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
-func (x *DeleteFileResponse) Reset() {
-	*x = DeleteFileResponse{}
+func (x *UploadFileStreamRequest) Reset() {
+	*x = UploadFileStreamRequest{}
 	mi := &file_pkg_gfs_gfs_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteFileResponse) String() string {
+func (x *UploadFileStreamRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteFileResponse) ProtoMessage() {}
+func (*UploadFileStreamRequest) ProtoMessage() {}
 
-func (x *DeleteFileResponse) ProtoReflect() protoreflect.Message {
+func (x *UploadFileStreamRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_pkg_gfs_gfs_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -717,48 +825,2484 @@ func (x *DeleteFileResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteFileResponse.ProtoReflect.Descriptor instead.
-func (*DeleteFileResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use UploadFileStreamRequest.ProtoReflect.Descriptor instead.
+func (*UploadFileStreamRequest) Descriptor() ([]byte, []int) {
 	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{13}
 }
 
-func (x *DeleteFileResponse) GetSuccess() bool {
+func (x *UploadFileStreamRequest) GetFilename() string {
 	if x != nil {
-		return x.Success
+		return x.Filename
 	}
-	return false
+	return ""
 }
 
-func (x *DeleteFileResponse) GetMessage() string {
+func (x *UploadFileStreamRequest) GetData() []byte {
 	if x != nil {
-		return x.Message
+		return x.Data
 	}
-	return ""
+	return nil
 }
 
-type GetChunkLocationsRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
-	ChunkIndex    int32                  `protobuf:"varint,2,opt,name=chunk_index,json=chunkIndex,proto3" json:"chunk_index,omitempty"`
+func (x *UploadFileStreamRequest) GetReplicationFactor() int32 {
+	if x != nil {
+		return x.ReplicationFactor
+	}
+	return 0
+}
+
+type DownloadFileStreamResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadFileStreamResponse) Reset() {
+	*x = DownloadFileStreamResponse{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadFileStreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadFileStreamResponse) ProtoMessage() {}
+
+func (x *DownloadFileStreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadFileStreamResponse.ProtoReflect.Descriptor instead.
+func (*DownloadFileStreamResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *DownloadFileStreamResponse) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// Lists the immediate children of a directory; an empty path means the root
+type ListFilesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Path  string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// Also return the attributes of every listed file in file_infos
+	IncludeAttributes bool `protobuf:"varint,2,opt,name=include_attributes,json=includeAttributes,proto3" json:"include_attributes,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *ListFilesRequest) Reset() {
+	*x = ListFilesRequest{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFilesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFilesRequest) ProtoMessage() {}
+
+func (x *ListFilesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFilesRequest.ProtoReflect.Descriptor instead.
+func (*ListFilesRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ListFilesRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ListFilesRequest) GetIncludeAttributes() bool {
+	if x != nil {
+		return x.IncludeAttributes
+	}
+	return false
+}
+
+type ListFilesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Files         []string               `protobuf:"bytes,2,rep,name=files,proto3" json:"files,omitempty"`
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	Directories   []string               `protobuf:"bytes,4,rep,name=directories,proto3" json:"directories,omitempty"`
+	FileInfos     []*FileInfo            `protobuf:"bytes,5,rep,name=file_infos,json=fileInfos,proto3" json:"file_infos,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFilesResponse) Reset() {
+	*x = ListFilesResponse{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFilesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFilesResponse) ProtoMessage() {}
+
+func (x *ListFilesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFilesResponse.ProtoReflect.Descriptor instead.
+func (*ListFilesResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListFilesResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ListFilesResponse) GetFiles() []string {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+func (x *ListFilesResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ListFilesResponse) GetDirectories() []string {
+	if x != nil {
+		return x.Directories
+	}
+	return nil
+}
+
+func (x *ListFilesResponse) GetFileInfos() []*FileInfo {
+	if x != nil {
+		return x.FileInfos
+	}
+	return nil
+}
+
+// Where one chunk of a file lives
+type ChunkInfo struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	ChunkHandle          string                 `protobuf:"bytes,1,opt,name=chunk_handle,json=chunkHandle,proto3" json:"chunk_handle,omitempty"`
+	ChunkserverAddresses []string               `protobuf:"bytes,2,rep,name=chunkserver_addresses,json=chunkserverAddresses,proto3" json:"chunkserver_addresses,omitempty"`
+	Version              uint64                 `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *ChunkInfo) Reset() {
+	*x = ChunkInfo{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChunkInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChunkInfo) ProtoMessage() {}
+
+func (x *ChunkInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChunkInfo.ProtoReflect.Descriptor instead.
+func (*ChunkInfo) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ChunkInfo) GetChunkHandle() string {
+	if x != nil {
+		return x.ChunkHandle
+	}
+	return ""
+}
+
+func (x *ChunkInfo) GetChunkserverAddresses() []string {
+	if x != nil {
+		return x.ChunkserverAddresses
+	}
+	return nil
+}
+
+func (x *ChunkInfo) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type FileInfo struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Path        string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	IsDirectory bool                   `protobuf:"varint,2,opt,name=is_directory,json=isDirectory,proto3" json:"is_directory,omitempty"`
+	Size        int64                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	ChunkCount  int32                  `protobuf:"varint,4,opt,name=chunk_count,json=chunkCount,proto3" json:"chunk_count,omitempty"`
+	Chunks      []*ChunkInfo           `protobuf:"bytes,5,rep,name=chunks,proto3" json:"chunks,omitempty"`
+	// Unix times in seconds; zero when unknown
+	CreatedAt         int64 `protobuf:"varint,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	ModifiedAt        int64 `protobuf:"varint,7,opt,name=modified_at,json=modifiedAt,proto3" json:"modified_at,omitempty"`
+	ReplicationFactor int32 `protobuf:"varint,8,opt,name=replication_factor,json=replicationFactor,proto3" json:"replication_factor,omitempty"`
+	// CRC-32C of the file contents as 8 hex digits; empty when unknown
+	Checksum      string `protobuf:"bytes,9,opt,name=checksum,proto3" json:"checksum,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileInfo) Reset() {
+	*x = FileInfo{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileInfo) ProtoMessage() {}
+
+func (x *FileInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileInfo.ProtoReflect.Descriptor instead.
+func (*FileInfo) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *FileInfo) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *FileInfo) GetIsDirectory() bool {
+	if x != nil {
+		return x.IsDirectory
+	}
+	return false
+}
+
+func (x *FileInfo) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *FileInfo) GetChunkCount() int32 {
+	if x != nil {
+		return x.ChunkCount
+	}
+	return 0
+}
+
+func (x *FileInfo) GetChunks() []*ChunkInfo {
+	if x != nil {
+		return x.Chunks
+	}
+	return nil
+}
+
+func (x *FileInfo) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *FileInfo) GetModifiedAt() int64 {
+	if x != nil {
+		return x.ModifiedAt
+	}
+	return 0
+}
+
+func (x *FileInfo) GetReplicationFactor() int32 {
+	if x != nil {
+		return x.ReplicationFactor
+	}
+	return 0
+}
+
+func (x *FileInfo) GetChecksum() string {
+	if x != nil {
+		return x.Checksum
+	}
+	return ""
+}
+
+type StatRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatRequest) Reset() {
+	*x = StatRequest{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatRequest) ProtoMessage() {}
+
+func (x *StatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatRequest.ProtoReflect.Descriptor instead.
+func (*StatRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *StatRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type StatResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Info          *FileInfo              `protobuf:"bytes,3,opt,name=info,proto3" json:"info,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatResponse) Reset() {
+	*x = StatResponse{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatResponse) ProtoMessage() {}
+
+func (x *StatResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatResponse.ProtoReflect.Descriptor instead.
+func (*StatResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *StatResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *StatResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *StatResponse) GetInfo() *FileInfo {
+	if x != nil {
+		return x.Info
+	}
+	return nil
+}
+
+type DeleteFileRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Filename string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	// Required to delete a directory together with everything below it
+	Recursive     bool `protobuf:"varint,2,opt,name=recursive,proto3" json:"recursive,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteFileRequest) Reset() {
+	*x = DeleteFileRequest{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteFileRequest) ProtoMessage() {}
+
+func (x *DeleteFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteFileRequest.ProtoReflect.Descriptor instead.
+func (*DeleteFileRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *DeleteFileRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *DeleteFileRequest) GetRecursive() bool {
+	if x != nil {
+		return x.Recursive
+	}
+	return false
+}
+
+type DeleteFileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteFileResponse) Reset() {
+	*x = DeleteFileResponse{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteFileResponse) ProtoMessage() {}
+
+func (x *DeleteFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteFileResponse.ProtoReflect.Descriptor instead.
+func (*DeleteFileResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *DeleteFileResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *DeleteFileResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type MkdirRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Path  string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// Create missing parent directories, and succeed if the directory exists
+	Parents       bool `protobuf:"varint,2,opt,name=parents,proto3" json:"parents,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MkdirRequest) Reset() {
+	*x = MkdirRequest{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MkdirRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MkdirRequest) ProtoMessage() {}
+
+func (x *MkdirRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MkdirRequest.ProtoReflect.Descriptor instead.
+func (*MkdirRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *MkdirRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *MkdirRequest) GetParents() bool {
+	if x != nil {
+		return x.Parents
+	}
+	return false
+}
+
+type MkdirResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MkdirResponse) Reset() {
+	*x = MkdirResponse{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MkdirResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MkdirResponse) ProtoMessage() {}
+
+func (x *MkdirResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MkdirResponse.ProtoReflect.Descriptor instead.
+func (*MkdirResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *MkdirResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *MkdirResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Removes an empty directory
+type RmdirRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RmdirRequest) Reset() {
+	*x = RmdirRequest{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RmdirRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RmdirRequest) ProtoMessage() {}
+
+func (x *RmdirRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RmdirRequest.ProtoReflect.Descriptor instead.
+func (*RmdirRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *RmdirRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type RmdirResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RmdirResponse) Reset() {
+	*x = RmdirResponse{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RmdirResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RmdirResponse) ProtoMessage() {}
+
+func (x *RmdirResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RmdirResponse.ProtoReflect.Descriptor instead.
+func (*RmdirResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *RmdirResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *RmdirResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Atomically moves a file or directory tree to a new path without touching chunk data
+type RenameRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Source      string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Destination string                 `protobuf:"bytes,2,opt,name=destination,proto3" json:"destination,omitempty"`
+	// Replace an existing destination file or empty directory instead of failing
+	Overwrite     bool `protobuf:"varint,3,opt,name=overwrite,proto3" json:"overwrite,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenameRequest) Reset() {
+	*x = RenameRequest{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenameRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenameRequest) ProtoMessage() {}
+
+func (x *RenameRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenameRequest.ProtoReflect.Descriptor instead.
+func (*RenameRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *RenameRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *RenameRequest) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+func (x *RenameRequest) GetOverwrite() bool {
+	if x != nil {
+		return x.Overwrite
+	}
+	return false
+}
+
+type RenameResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenameResponse) Reset() {
+	*x = RenameResponse{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenameResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenameResponse) ProtoMessage() {}
+
+func (x *RenameResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenameResponse.ProtoReflect.Descriptor instead.
+func (*RenameResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *RenameResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *RenameResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type GetChunkLocationsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	ChunkIndex    int32                  `protobuf:"varint,2,opt,name=chunk_index,json=chunkIndex,proto3" json:"chunk_index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetChunkLocationsRequest) Reset() {
+	*x = GetChunkLocationsRequest{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetChunkLocationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetChunkLocationsRequest) ProtoMessage() {}
+
+func (x *GetChunkLocationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetChunkLocationsRequest.ProtoReflect.Descriptor instead.
+func (*GetChunkLocationsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *GetChunkLocationsRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *GetChunkLocationsRequest) GetChunkIndex() int32 {
+	if x != nil {
+		return x.ChunkIndex
+	}
+	return 0
+}
+
+type GetChunkLocationsResponse struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	ChunkserverAddresses []string               `protobuf:"bytes,1,rep,name=chunkserver_addresses,json=chunkserverAddresses,proto3" json:"chunkserver_addresses,omitempty"`
+	ChunkHandle          string                 `protobuf:"bytes,2,opt,name=chunk_handle,json=chunkHandle,proto3" json:"chunk_handle,omitempty"`
+	ChunkCount           int32                  `protobuf:"varint,3,opt,name=chunk_count,json=chunkCount,proto3" json:"chunk_count,omitempty"`
+	FileSize             int64                  `protobuf:"varint,4,opt,name=file_size,json=fileSize,proto3" json:"file_size,omitempty"`
+	Version              uint64                 `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *GetChunkLocationsResponse) Reset() {
+	*x = GetChunkLocationsResponse{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetChunkLocationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetChunkLocationsResponse) ProtoMessage() {}
+
+func (x *GetChunkLocationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetChunkLocationsResponse.ProtoReflect.Descriptor instead.
+func (*GetChunkLocationsResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *GetChunkLocationsResponse) GetChunkserverAddresses() []string {
+	if x != nil {
+		return x.ChunkserverAddresses
+	}
+	return nil
+}
+
+func (x *GetChunkLocationsResponse) GetChunkHandle() string {
+	if x != nil {
+		return x.ChunkHandle
+	}
+	return ""
+}
+
+func (x *GetChunkLocationsResponse) GetChunkCount() int32 {
+	if x != nil {
+		return x.ChunkCount
+	}
+	return 0
+}
+
+func (x *GetChunkLocationsResponse) GetFileSize() int64 {
+	if x != nil {
+		return x.FileSize
+	}
+	return 0
+}
+
+func (x *GetChunkLocationsResponse) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type AllocateChunkRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Filename   string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	ChunkIndex int32                  `protobuf:"varint,2,opt,name=chunk_index,json=chunkIndex,proto3" json:"chunk_index,omitempty"`
+	// Replicas to place; 0 keeps the factor of the file being overwritten, or the default
+	ReplicationFactor int32 `protobuf:"varint,3,opt,name=replication_factor,json=replicationFactor,proto3" json:"replication_factor,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *AllocateChunkRequest) Reset() {
+	*x = AllocateChunkRequest{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AllocateChunkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AllocateChunkRequest) ProtoMessage() {}
+
+func (x *AllocateChunkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AllocateChunkRequest.ProtoReflect.Descriptor instead.
+func (*AllocateChunkRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *AllocateChunkRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *AllocateChunkRequest) GetChunkIndex() int32 {
+	if x != nil {
+		return x.ChunkIndex
+	}
+	return 0
+}
+
+func (x *AllocateChunkRequest) GetReplicationFactor() int32 {
+	if x != nil {
+		return x.ReplicationFactor
+	}
+	return 0
+}
+
+type AllocateChunkResponse struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Success              bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message              string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	ChunkHandle          string                 `protobuf:"bytes,3,opt,name=chunk_handle,json=chunkHandle,proto3" json:"chunk_handle,omitempty"`
+	ChunkserverAddresses []string               `protobuf:"bytes,4,rep,name=chunkserver_addresses,json=chunkserverAddresses,proto3" json:"chunkserver_addresses,omitempty"`
+	ChunkSize            int64                  `protobuf:"varint,5,opt,name=chunk_size,json=chunkSize,proto3" json:"chunk_size,omitempty"`
+	Version              uint64                 `protobuf:"varint,6,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *AllocateChunkResponse) Reset() {
+	*x = AllocateChunkResponse{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AllocateChunkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AllocateChunkResponse) ProtoMessage() {}
+
+func (x *AllocateChunkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AllocateChunkResponse.ProtoReflect.Descriptor instead.
+func (*AllocateChunkResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *AllocateChunkResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *AllocateChunkResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *AllocateChunkResponse) GetChunkHandle() string {
+	if x != nil {
+		return x.ChunkHandle
+	}
+	return ""
+}
+
+func (x *AllocateChunkResponse) GetChunkserverAddresses() []string {
+	if x != nil {
+		return x.ChunkserverAddresses
+	}
+	return nil
+}
+
+func (x *AllocateChunkResponse) GetChunkSize() int64 {
+	if x != nil {
+		return x.ChunkSize
+	}
+	return 0
+}
+
+func (x *AllocateChunkResponse) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+// A chunk written directly by a client, with the replicas that stored it
+type CommittedChunk struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	ChunkHandle          string                 `protobuf:"bytes,1,opt,name=chunk_handle,json=chunkHandle,proto3" json:"chunk_handle,omitempty"`
+	ChunkserverAddresses []string               `protobuf:"bytes,2,rep,name=chunkserver_addresses,json=chunkserverAddresses,proto3" json:"chunkserver_addresses,omitempty"`
+	Size                 int64                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	Version              uint64                 `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *CommittedChunk) Reset() {
+	*x = CommittedChunk{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommittedChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommittedChunk) ProtoMessage() {}
+
+func (x *CommittedChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommittedChunk.ProtoReflect.Descriptor instead.
+func (*CommittedChunk) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *CommittedChunk) GetChunkHandle() string {
+	if x != nil {
+		return x.ChunkHandle
+	}
+	return ""
+}
+
+func (x *CommittedChunk) GetChunkserverAddresses() []string {
+	if x != nil {
+		return x.ChunkserverAddresses
+	}
+	return nil
+}
+
+func (x *CommittedChunk) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *CommittedChunk) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type CommitFileRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Filename string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	Chunks   []*CommittedChunk      `protobuf:"bytes,2,rep,name=chunks,proto3" json:"chunks,omitempty"`
+	// CRC-32C of the whole file as 8 hex digits, if the client computed it
+	Checksum string `protobuf:"bytes,3,opt,name=checksum,proto3" json:"checksum,omitempty"`
+	// Replicas to keep of each chunk; 0 keeps the factor of the file being
+	// overwritten, or the default
+	ReplicationFactor int32 `protobuf:"varint,4,opt,name=replication_factor,json=replicationFactor,proto3" json:"replication_factor,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *CommitFileRequest) Reset() {
+	*x = CommitFileRequest{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommitFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommitFileRequest) ProtoMessage() {}
+
+func (x *CommitFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommitFileRequest.ProtoReflect.Descriptor instead.
+func (*CommitFileRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *CommitFileRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *CommitFileRequest) GetChunks() []*CommittedChunk {
+	if x != nil {
+		return x.Chunks
+	}
+	return nil
+}
+
+func (x *CommitFileRequest) GetChecksum() string {
+	if x != nil {
+		return x.Checksum
+	}
+	return ""
+}
+
+func (x *CommitFileRequest) GetReplicationFactor() int32 {
+	if x != nil {
+		return x.ReplicationFactor
+	}
+	return 0
+}
+
+type CommitFileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommitFileResponse) Reset() {
+	*x = CommitFileResponse{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommitFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommitFileResponse) ProtoMessage() {}
+
+func (x *CommitFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommitFileResponse.ProtoReflect.Descriptor instead.
+func (*CommitFileResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *CommitFileResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *CommitFileResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type HeartbeatRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Stable identity persisted by the chunkserver
+	ChunkserverId string            `protobuf:"bytes,1,opt,name=chunkserver_id,json=chunkserverId,proto3" json:"chunkserver_id,omitempty"`
+	Stats         *ChunkserverStats `protobuf:"bytes,2,opt,name=stats,proto3" json:"stats,omitempty"`
+	// Address clients and peers should dial; defaults to chunkserver_id if empty
+	Address string `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	// Outcomes of commands received with earlier heartbeat responses
+	CommandResults []*CommandResult `protobuf:"bytes,4,rep,name=command_results,json=commandResults,proto3" json:"command_results,omitempty"`
+	Topology       *Topology        `protobuf:"bytes,5,opt,name=topology,proto3" json:"topology,omitempty"`
+	// Chunks that failed checksum verification since the last heartbeat
+	CorruptChunks []string `protobuf:"bytes,6,rep,name=corrupt_chunks,json=corruptChunks,proto3" json:"corrupt_chunks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeartbeatRequest) Reset() {
+	*x = HeartbeatRequest{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatRequest) ProtoMessage() {}
+
+func (x *HeartbeatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatRequest.ProtoReflect.Descriptor instead.
+func (*HeartbeatRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *HeartbeatRequest) GetChunkserverId() string {
+	if x != nil {
+		return x.ChunkserverId
+	}
+	return ""
+}
+
+func (x *HeartbeatRequest) GetStats() *ChunkserverStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+func (x *HeartbeatRequest) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *HeartbeatRequest) GetCommandResults() []*CommandResult {
+	if x != nil {
+		return x.CommandResults
+	}
+	return nil
+}
+
+func (x *HeartbeatRequest) GetTopology() *Topology {
+	if x != nil {
+		return x.Topology
+	}
+	return nil
+}
+
+func (x *HeartbeatRequest) GetCorruptChunks() []string {
+	if x != nil {
+		return x.CorruptChunks
+	}
+	return nil
+}
+
+// Failure domains of a chunkserver; the master spreads the replicas of a chunk
+// across them. Empty labels are unknown.
+type Topology struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Zone          string                 `protobuf:"bytes,1,opt,name=zone,proto3" json:"zone,omitempty"`
+	Rack          string                 `protobuf:"bytes,2,opt,name=rack,proto3" json:"rack,omitempty"`
+	Host          string                 `protobuf:"bytes,3,opt,name=host,proto3" json:"host,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Topology) Reset() {
+	*x = Topology{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Topology) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Topology) ProtoMessage() {}
+
+func (x *Topology) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Topology.ProtoReflect.Descriptor instead.
+func (*Topology) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *Topology) GetZone() string {
+	if x != nil {
+		return x.Zone
+	}
+	return ""
+}
+
+func (x *Topology) GetRack() string {
+	if x != nil {
+		return x.Rack
+	}
+	return ""
+}
+
+func (x *Topology) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+// Storage and load of a chunkserver, sent with every heartbeat
+type ChunkserverStats struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	ChunkCount int32                  `protobuf:"varint,1,opt,name=chunk_count,json=chunkCount,proto3" json:"chunk_count,omitempty"`
+	UsedBytes  int64                  `protobuf:"varint,2,opt,name=used_bytes,json=usedBytes,proto3" json:"used_bytes,omitempty"`
+	FreeBytes  int64                  `protobuf:"varint,3,opt,name=free_bytes,json=freeBytes,proto3" json:"free_bytes,omitempty"`
+	// RPCs the chunkserver is serving right now
+	ActiveOperations int32 `protobuf:"varint,4,opt,name=active_operations,json=activeOperations,proto3" json:"active_operations,omitempty"`
+	// Set while the chunkserver refuses new chunks on the master's request
+	Draining bool `protobuf:"varint,5,opt,name=draining,proto3" json:"draining,omitempty"`
+	// Size of the file system holding the chunks; 0 if unknown. It may hold
+	// other data, so total_bytes - free_bytes can exceed used_bytes.
+	TotalBytes int64 `protobuf:"varint,6,opt,name=total_bytes,json=totalBytes,proto3" json:"total_bytes,omitempty"`
+	// Unset until the background scrubber starts its first pass
+	Scrub         *ScrubStatus `protobuf:"bytes,7,opt,name=scrub,proto3" json:"scrub,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChunkserverStats) Reset() {
+	*x = ChunkserverStats{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChunkserverStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChunkserverStats) ProtoMessage() {}
+
+func (x *ChunkserverStats) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChunkserverStats.ProtoReflect.Descriptor instead.
+func (*ChunkserverStats) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *ChunkserverStats) GetChunkCount() int32 {
+	if x != nil {
+		return x.ChunkCount
+	}
+	return 0
+}
+
+func (x *ChunkserverStats) GetUsedBytes() int64 {
+	if x != nil {
+		return x.UsedBytes
+	}
+	return 0
+}
+
+func (x *ChunkserverStats) GetFreeBytes() int64 {
+	if x != nil {
+		return x.FreeBytes
+	}
+	return 0
+}
+
+func (x *ChunkserverStats) GetActiveOperations() int32 {
+	if x != nil {
+		return x.ActiveOperations
+	}
+	return 0
+}
+
+func (x *ChunkserverStats) GetDraining() bool {
+	if x != nil {
+		return x.Draining
+	}
+	return false
+}
+
+func (x *ChunkserverStats) GetTotalBytes() int64 {
+	if x != nil {
+		return x.TotalBytes
+	}
+	return 0
+}
+
+func (x *ChunkserverStats) GetScrub() *ScrubStatus {
+	if x != nil {
+		return x.Scrub
+	}
+	return nil
+}
+
+// Progress of a chunkserver's background checksum scrubber
+type ScrubStatus struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Set while a pass over all chunks is in progress
+	Running bool `protobuf:"varint,1,opt,name=running,proto3" json:"running,omitempty"`
+	// Progress of the current pass, or of the last one if none is running
+	ScrubbedChunks int32 `protobuf:"varint,2,opt,name=scrubbed_chunks,json=scrubbedChunks,proto3" json:"scrubbed_chunks,omitempty"`
+	TotalChunks    int32 `protobuf:"varint,3,opt,name=total_chunks,json=totalChunks,proto3" json:"total_chunks,omitempty"`
+	ScrubbedBytes  int64 `protobuf:"varint,4,opt,name=scrubbed_bytes,json=scrubbedBytes,proto3" json:"scrubbed_bytes,omitempty"`
+	TotalBytes     int64 `protobuf:"varint,5,opt,name=total_bytes,json=totalBytes,proto3" json:"total_bytes,omitempty"`
+	// When the last full pass finished, in Unix seconds; 0 if none has yet
+	LastScrub int64 `protobuf:"varint,6,opt,name=last_scrub,json=lastScrub,proto3" json:"last_scrub,omitempty"`
+	// Corrupt chunks the scrubber found since the chunkserver started
+	CorruptChunks int64 `protobuf:"varint,7,opt,name=corrupt_chunks,json=corruptChunks,proto3" json:"corrupt_chunks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScrubStatus) Reset() {
+	*x = ScrubStatus{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScrubStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScrubStatus) ProtoMessage() {}
+
+func (x *ScrubStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScrubStatus.ProtoReflect.Descriptor instead.
+func (*ScrubStatus) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *ScrubStatus) GetRunning() bool {
+	if x != nil {
+		return x.Running
+	}
+	return false
+}
+
+func (x *ScrubStatus) GetScrubbedChunks() int32 {
+	if x != nil {
+		return x.ScrubbedChunks
+	}
+	return 0
+}
+
+func (x *ScrubStatus) GetTotalChunks() int32 {
+	if x != nil {
+		return x.TotalChunks
+	}
+	return 0
+}
+
+func (x *ScrubStatus) GetScrubbedBytes() int64 {
+	if x != nil {
+		return x.ScrubbedBytes
+	}
+	return 0
+}
+
+func (x *ScrubStatus) GetTotalBytes() int64 {
+	if x != nil {
+		return x.TotalBytes
+	}
+	return 0
+}
+
+func (x *ScrubStatus) GetLastScrub() int64 {
+	if x != nil {
+		return x.LastScrub
+	}
+	return 0
+}
+
+func (x *ScrubStatus) GetCorruptChunks() int64 {
+	if x != nil {
+		return x.CorruptChunks
+	}
+	return 0
+}
+
+type HeartbeatResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Message string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	// Work for the chunkserver; results are acknowledged with the next heartbeat
+	Commands      []*ChunkserverCommand `protobuf:"bytes,2,rep,name=commands,proto3" json:"commands,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeartbeatResponse) Reset() {
+	*x = HeartbeatResponse{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatResponse) ProtoMessage() {}
+
+func (x *HeartbeatResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatResponse.ProtoReflect.Descriptor instead.
+func (*HeartbeatResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *HeartbeatResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *HeartbeatResponse) GetCommands() []*ChunkserverCommand {
+	if x != nil {
+		return x.Commands
+	}
+	return nil
+}
+
+// A maintenance task the master hands to a chunkserver through heartbeats
+type ChunkserverCommand struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Unique per master run; echoed back in CommandResult
+	Id            uint64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type          ChunkserverCommand_Type `protobuf:"varint,2,opt,name=type,proto3,enum=gfs.ChunkserverCommand_Type" json:"type,omitempty"`
+	ChunkHandles  []string                `protobuf:"bytes,3,rep,name=chunk_handles,json=chunkHandles,proto3" json:"chunk_handles,omitempty"`
+	ChunkHandle   string                  `protobuf:"bytes,4,opt,name=chunk_handle,json=chunkHandle,proto3" json:"chunk_handle,omitempty"`
+	Version       uint64                  `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`
+	SourceAddress string                  `protobuf:"bytes,6,opt,name=source_address,json=sourceAddress,proto3" json:"source_address,omitempty"`
+	Drain         bool                    `protobuf:"varint,7,opt,name=drain,proto3" json:"drain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChunkserverCommand) Reset() {
+	*x = ChunkserverCommand{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChunkserverCommand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChunkserverCommand) ProtoMessage() {}
+
+func (x *ChunkserverCommand) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChunkserverCommand.ProtoReflect.Descriptor instead.
+func (*ChunkserverCommand) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *ChunkserverCommand) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ChunkserverCommand) GetType() ChunkserverCommand_Type {
+	if x != nil {
+		return x.Type
+	}
+	return ChunkserverCommand_UNKNOWN
+}
+
+func (x *ChunkserverCommand) GetChunkHandles() []string {
+	if x != nil {
+		return x.ChunkHandles
+	}
+	return nil
+}
+
+func (x *ChunkserverCommand) GetChunkHandle() string {
+	if x != nil {
+		return x.ChunkHandle
+	}
+	return ""
+}
+
+func (x *ChunkserverCommand) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *ChunkserverCommand) GetSourceAddress() string {
+	if x != nil {
+		return x.SourceAddress
+	}
+	return ""
+}
+
+func (x *ChunkserverCommand) GetDrain() bool {
+	if x != nil {
+		return x.Drain
+	}
+	return false
+}
+
+type CommandResult struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	CommandId uint64                 `protobuf:"varint,1,opt,name=command_id,json=commandId,proto3" json:"command_id,omitempty"`
+	Success   bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	Message   string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	// Bytes copied by a REPLICATE command
+	Size          int64 `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommandResult) Reset() {
+	*x = CommandResult{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommandResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandResult) ProtoMessage() {}
+
+func (x *CommandResult) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandResult.ProtoReflect.Descriptor instead.
+func (*CommandResult) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *CommandResult) GetCommandId() uint64 {
+	if x != nil {
+		return x.CommandId
+	}
+	return 0
+}
+
+func (x *CommandResult) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *CommandResult) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *CommandResult) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+type ChunkReport struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChunkHandle   string                 `protobuf:"bytes,1,opt,name=chunk_handle,json=chunkHandle,proto3" json:"chunk_handle,omitempty"`
+	Size          int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	Version       uint64                 `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChunkReport) Reset() {
+	*x = ChunkReport{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChunkReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChunkReport) ProtoMessage() {}
+
+func (x *ChunkReport) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChunkReport.ProtoReflect.Descriptor instead.
+func (*ChunkReport) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *ChunkReport) GetChunkHandle() string {
+	if x != nil {
+		return x.ChunkHandle
+	}
+	return ""
+}
+
+func (x *ChunkReport) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *ChunkReport) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+// The full list of chunks a chunkserver holds
+type ReportChunksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChunkserverId string                 `protobuf:"bytes,1,opt,name=chunkserver_id,json=chunkserverId,proto3" json:"chunkserver_id,omitempty"`
+	Chunks        []*ChunkReport         `protobuf:"bytes,2,rep,name=chunks,proto3" json:"chunks,omitempty"`
+	Address       string                 `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	Topology      *Topology              `protobuf:"bytes,4,opt,name=topology,proto3" json:"topology,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReportChunksRequest) Reset() {
+	*x = ReportChunksRequest{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportChunksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportChunksRequest) ProtoMessage() {}
+
+func (x *ReportChunksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportChunksRequest.ProtoReflect.Descriptor instead.
+func (*ReportChunksRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *ReportChunksRequest) GetChunkserverId() string {
+	if x != nil {
+		return x.ChunkserverId
+	}
+	return ""
+}
+
+func (x *ReportChunksRequest) GetChunks() []*ChunkReport {
+	if x != nil {
+		return x.Chunks
+	}
+	return nil
+}
+
+func (x *ReportChunksRequest) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *ReportChunksRequest) GetTopology() *Topology {
+	if x != nil {
+		return x.Topology
+	}
+	return nil
+}
+
+type ReportChunksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReportChunksResponse) Reset() {
+	*x = ReportChunksResponse{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportChunksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportChunksResponse) ProtoMessage() {}
+
+func (x *ReportChunksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportChunksResponse.ProtoReflect.Descriptor instead.
+func (*ReportChunksResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *ReportChunksResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ReportChunksResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type ListChunkserversRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListChunkserversRequest) Reset() {
+	*x = ListChunkserversRequest{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListChunkserversRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListChunkserversRequest) ProtoMessage() {}
+
+func (x *ListChunkserversRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListChunkserversRequest.ProtoReflect.Descriptor instead.
+func (*ListChunkserversRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{46}
+}
+
+type ChunkserverStatus struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Address string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	// Unix time in seconds of the last heartbeat
+	LastSeen int64             `protobuf:"varint,2,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`
+	Healthy  bool              `protobuf:"varint,3,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	Stats    *ChunkserverStats `protobuf:"bytes,4,opt,name=stats,proto3" json:"stats,omitempty"`
+	Id       string            `protobuf:"bytes,5,opt,name=id,proto3" json:"id,omitempty"`
+	Topology *Topology         `protobuf:"bytes,6,opt,name=topology,proto3" json:"topology,omitempty"`
+	// Set while the disk is above the master's high-water mark; the master places
+	// no new chunks on it
+	Full bool `protobuf:"varint,7,opt,name=full,proto3" json:"full,omitempty"`
+	// Set while the master drains the chunkserver so it can be removed
+	Draining bool `protobuf:"varint,8,opt,name=draining,proto3" json:"draining,omitempty"`
+	// Chunks on a draining chunkserver that still lack their full replica count elsewhere
+	ChunksToDrain int32 `protobuf:"varint,9,opt,name=chunks_to_drain,json=chunksToDrain,proto3" json:"chunks_to_drain,omitempty"`
+	// Set once a draining chunkserver can be removed without losing replicas
+	Drained       bool `protobuf:"varint,10,opt,name=drained,proto3" json:"drained,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChunkserverStatus) Reset() {
+	*x = ChunkserverStatus{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChunkserverStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChunkserverStatus) ProtoMessage() {}
+
+func (x *ChunkserverStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChunkserverStatus.ProtoReflect.Descriptor instead.
+func (*ChunkserverStatus) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *ChunkserverStatus) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *ChunkserverStatus) GetLastSeen() int64 {
+	if x != nil {
+		return x.LastSeen
+	}
+	return 0
+}
+
+func (x *ChunkserverStatus) GetHealthy() bool {
+	if x != nil {
+		return x.Healthy
+	}
+	return false
+}
+
+func (x *ChunkserverStatus) GetStats() *ChunkserverStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+func (x *ChunkserverStatus) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ChunkserverStatus) GetTopology() *Topology {
+	if x != nil {
+		return x.Topology
+	}
+	return nil
+}
+
+func (x *ChunkserverStatus) GetFull() bool {
+	if x != nil {
+		return x.Full
+	}
+	return false
+}
+
+func (x *ChunkserverStatus) GetDraining() bool {
+	if x != nil {
+		return x.Draining
+	}
+	return false
+}
+
+func (x *ChunkserverStatus) GetChunksToDrain() int32 {
+	if x != nil {
+		return x.ChunksToDrain
+	}
+	return 0
+}
+
+func (x *ChunkserverStatus) GetDrained() bool {
+	if x != nil {
+		return x.Drained
+	}
+	return false
+}
+
+type ListChunkserversResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Chunkservers  []*ChunkserverStatus   `protobuf:"bytes,3,rep,name=chunkservers,proto3" json:"chunkservers,omitempty"`
+	Replication   *ReplicationStatus     `protobuf:"bytes,4,opt,name=replication,proto3" json:"replication,omitempty"`
+	Balancer      *BalancerStatus        `protobuf:"bytes,5,opt,name=balancer,proto3" json:"balancer,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListChunkserversResponse) Reset() {
+	*x = ListChunkserversResponse{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListChunkserversResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListChunkserversResponse) ProtoMessage() {}
+
+func (x *ListChunkserversResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListChunkserversResponse.ProtoReflect.Descriptor instead.
+func (*ListChunkserversResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *ListChunkserversResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ListChunkserversResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ListChunkserversResponse) GetChunkservers() []*ChunkserverStatus {
+	if x != nil {
+		return x.Chunkservers
+	}
+	return nil
+}
+
+func (x *ListChunkserversResponse) GetReplication() *ReplicationStatus {
+	if x != nil {
+		return x.Replication
+	}
+	return nil
+}
+
+func (x *ListChunkserversResponse) GetBalancer() *BalancerStatus {
+	if x != nil {
+		return x.Balancer
+	}
+	return nil
+}
+
+// Progress of re-replicating under-replicated chunks
+type ReplicationStatus struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Chunks waiting for more replicas
+	QueuedChunks int32 `protobuf:"varint,1,opt,name=queued_chunks,json=queuedChunks,proto3" json:"queued_chunks,omitempty"`
+	// Replicas those chunks are missing
+	MissingReplicas int32 `protobuf:"varint,2,opt,name=missing_replicas,json=missingReplicas,proto3" json:"missing_replicas,omitempty"`
+	// Chunk copies in progress between chunkservers
+	ActiveCopies  int32 `protobuf:"varint,3,opt,name=active_copies,json=activeCopies,proto3" json:"active_copies,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplicationStatus) Reset() {
+	*x = ReplicationStatus{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplicationStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplicationStatus) ProtoMessage() {}
+
+func (x *ReplicationStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplicationStatus.ProtoReflect.Descriptor instead.
+func (*ReplicationStatus) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *ReplicationStatus) GetQueuedChunks() int32 {
+	if x != nil {
+		return x.QueuedChunks
+	}
+	return 0
+}
+
+func (x *ReplicationStatus) GetMissingReplicas() int32 {
+	if x != nil {
+		return x.MissingReplicas
+	}
+	return 0
+}
+
+func (x *ReplicationStatus) GetActiveCopies() int32 {
+	if x != nil {
+		return x.ActiveCopies
+	}
+	return 0
+}
+
+// Changes how many replicas the master keeps of a file's chunks; replicas are
+// added or removed in the background
+type SetReplicationRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Path              string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	ReplicationFactor int32                  `protobuf:"varint,2,opt,name=replication_factor,json=replicationFactor,proto3" json:"replication_factor,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *SetReplicationRequest) Reset() {
+	*x = SetReplicationRequest{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetReplicationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetReplicationRequest) ProtoMessage() {}
+
+func (x *SetReplicationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetReplicationRequest.ProtoReflect.Descriptor instead.
+func (*SetReplicationRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *SetReplicationRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *SetReplicationRequest) GetReplicationFactor() int32 {
+	if x != nil {
+		return x.ReplicationFactor
+	}
+	return 0
+}
+
+type SetReplicationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetChunkLocationsRequest) Reset() {
-	*x = GetChunkLocationsRequest{}
-	mi := &file_pkg_gfs_gfs_proto_msgTypes[14]
+func (x *SetReplicationResponse) Reset() {
+	*x = SetReplicationResponse{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[51]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetChunkLocationsRequest) String() string {
+func (x *SetReplicationResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetChunkLocationsRequest) ProtoMessage() {}
+func (*SetReplicationResponse) ProtoMessage() {}
 
-func (x *GetChunkLocationsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_gfs_gfs_proto_msgTypes[14]
+func (x *SetReplicationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[51]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -769,48 +3313,94 @@ func (x *GetChunkLocationsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetChunkLocationsRequest.ProtoReflect.Descriptor instead.
-func (*GetChunkLocationsRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{14}
+// Deprecated: Use SetReplicationResponse.ProtoReflect.Descriptor instead.
+func (*SetReplicationResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{51}
 }
 
-func (x *GetChunkLocationsRequest) GetFilename() string {
+func (x *SetReplicationResponse) GetSuccess() bool {
 	if x != nil {
-		return x.Filename
+		return x.Success
+	}
+	return false
+}
+
+func (x *SetReplicationResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
 	}
 	return ""
 }
 
-func (x *GetChunkLocationsRequest) GetChunkIndex() int32 {
+// Starts or stops moving chunks from the fullest chunkservers to the emptiest ones
+type SetBalancerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Running       bool                   `protobuf:"varint,1,opt,name=running,proto3" json:"running,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetBalancerRequest) Reset() {
+	*x = SetBalancerRequest{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetBalancerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetBalancerRequest) ProtoMessage() {}
+
+func (x *SetBalancerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[52]
 	if x != nil {
-		return x.ChunkIndex
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-type GetChunkLocationsResponse struct {
-	state                protoimpl.MessageState `protogen:"open.v1"`
-	ChunkserverAddresses []string               `protobuf:"bytes,1,rep,name=chunkserver_addresses,json=chunkserverAddresses,proto3" json:"chunkserver_addresses,omitempty"`
-	ChunkHandle          string                 `protobuf:"bytes,2,opt,name=chunk_handle,json=chunkHandle,proto3" json:"chunk_handle,omitempty"`
-	unknownFields        protoimpl.UnknownFields
-	sizeCache            protoimpl.SizeCache
+// Deprecated: Use SetBalancerRequest.ProtoReflect.Descriptor instead.
+func (*SetBalancerRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{52}
 }
 
-func (x *GetChunkLocationsResponse) Reset() {
-	*x = GetChunkLocationsResponse{}
-	mi := &file_pkg_gfs_gfs_proto_msgTypes[15]
+func (x *SetBalancerRequest) GetRunning() bool {
+	if x != nil {
+		return x.Running
+	}
+	return false
+}
+
+type SetBalancerResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Status        *BalancerStatus        `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetBalancerResponse) Reset() {
+	*x = SetBalancerResponse{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[53]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetChunkLocationsResponse) String() string {
+func (x *SetBalancerResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetChunkLocationsResponse) ProtoMessage() {}
+func (*SetBalancerResponse) ProtoMessage() {}
 
-func (x *GetChunkLocationsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_gfs_gfs_proto_msgTypes[15]
+func (x *SetBalancerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[53]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -821,47 +3411,138 @@ func (x *GetChunkLocationsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetChunkLocationsResponse.ProtoReflect.Descriptor instead.
-func (*GetChunkLocationsResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{15}
+// Deprecated: Use SetBalancerResponse.ProtoReflect.Descriptor instead.
+func (*SetBalancerResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{53}
 }
 
-func (x *GetChunkLocationsResponse) GetChunkserverAddresses() []string {
+func (x *SetBalancerResponse) GetSuccess() bool {
 	if x != nil {
-		return x.ChunkserverAddresses
+		return x.Success
 	}
-	return nil
+	return false
 }
 
-func (x *GetChunkLocationsResponse) GetChunkHandle() string {
+func (x *SetBalancerResponse) GetMessage() string {
 	if x != nil {
-		return x.ChunkHandle
+		return x.Message
 	}
 	return ""
 }
 
-type HeartbeatRequest struct {
+func (x *SetBalancerResponse) GetStatus() *BalancerStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+// Progress of evening out disk utilization across chunkservers
+type BalancerStatus struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Running bool                   `protobuf:"varint,1,opt,name=running,proto3" json:"running,omitempty"`
+	// Largest difference between the disk utilization of an available chunkserver
+	// and the cluster average, as a fraction
+	Skew float64 `protobuf:"fixed64,2,opt,name=skew,proto3" json:"skew,omitempty"`
+	// Chunk moves in progress
+	ActiveMoves int32 `protobuf:"varint,3,opt,name=active_moves,json=activeMoves,proto3" json:"active_moves,omitempty"`
+	// Chunks and bytes moved since the master started
+	MovedChunks   int64 `protobuf:"varint,4,opt,name=moved_chunks,json=movedChunks,proto3" json:"moved_chunks,omitempty"`
+	MovedBytes    int64 `protobuf:"varint,5,opt,name=moved_bytes,json=movedBytes,proto3" json:"moved_bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BalancerStatus) Reset() {
+	*x = BalancerStatus{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BalancerStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BalancerStatus) ProtoMessage() {}
+
+func (x *BalancerStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BalancerStatus.ProtoReflect.Descriptor instead.
+func (*BalancerStatus) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *BalancerStatus) GetRunning() bool {
+	if x != nil {
+		return x.Running
+	}
+	return false
+}
+
+func (x *BalancerStatus) GetSkew() float64 {
+	if x != nil {
+		return x.Skew
+	}
+	return 0
+}
+
+func (x *BalancerStatus) GetActiveMoves() int32 {
+	if x != nil {
+		return x.ActiveMoves
+	}
+	return 0
+}
+
+func (x *BalancerStatus) GetMovedChunks() int64 {
+	if x != nil {
+		return x.MovedChunks
+	}
+	return 0
+}
+
+func (x *BalancerStatus) GetMovedBytes() int64 {
+	if x != nil {
+		return x.MovedBytes
+	}
+	return 0
+}
+
+// Starts or cancels draining a chunkserver. A draining chunkserver gets no new
+// chunks and keeps serving reads while its chunks are copied elsewhere.
+type DrainChunkserverRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ChunkserverId string                 `protobuf:"bytes,1,opt,name=chunkserver_id,json=chunkserverId,proto3" json:"chunkserver_id,omitempty"`
+	Drain         bool                   `protobuf:"varint,2,opt,name=drain,proto3" json:"drain,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HeartbeatRequest) Reset() {
-	*x = HeartbeatRequest{}
-	mi := &file_pkg_gfs_gfs_proto_msgTypes[16]
+func (x *DrainChunkserverRequest) Reset() {
+	*x = DrainChunkserverRequest{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[55]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HeartbeatRequest) String() string {
+func (x *DrainChunkserverRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HeartbeatRequest) ProtoMessage() {}
+func (*DrainChunkserverRequest) ProtoMessage() {}
 
-func (x *HeartbeatRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_gfs_gfs_proto_msgTypes[16]
+func (x *DrainChunkserverRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[55]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -872,40 +3553,48 @@ func (x *HeartbeatRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HeartbeatRequest.ProtoReflect.Descriptor instead.
-func (*HeartbeatRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{16}
+// Deprecated: Use DrainChunkserverRequest.ProtoReflect.Descriptor instead.
+func (*DrainChunkserverRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{55}
 }
 
-func (x *HeartbeatRequest) GetChunkserverId() string {
+func (x *DrainChunkserverRequest) GetChunkserverId() string {
 	if x != nil {
 		return x.ChunkserverId
 	}
 	return ""
 }
 
-type HeartbeatResponse struct {
+func (x *DrainChunkserverRequest) GetDrain() bool {
+	if x != nil {
+		return x.Drain
+	}
+	return false
+}
+
+type DrainChunkserverResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HeartbeatResponse) Reset() {
-	*x = HeartbeatResponse{}
-	mi := &file_pkg_gfs_gfs_proto_msgTypes[17]
+func (x *DrainChunkserverResponse) Reset() {
+	*x = DrainChunkserverResponse{}
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[56]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HeartbeatResponse) String() string {
+func (x *DrainChunkserverResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HeartbeatResponse) ProtoMessage() {}
+func (*DrainChunkserverResponse) ProtoMessage() {}
 
-func (x *HeartbeatResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_gfs_gfs_proto_msgTypes[17]
+func (x *DrainChunkserverResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_gfs_gfs_proto_msgTypes[56]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -916,12 +3605,19 @@ func (x *HeartbeatResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HeartbeatResponse.ProtoReflect.Descriptor instead.
-func (*HeartbeatResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{17}
+// Deprecated: Use DrainChunkserverResponse.ProtoReflect.Descriptor instead.
+func (*DrainChunkserverResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_gfs_gfs_proto_rawDescGZIP(), []int{56}
 }
 
-func (x *HeartbeatResponse) GetMessage() string {
+func (x *DrainChunkserverResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *DrainChunkserverResponse) GetMessage() string {
 	if x != nil {
 		return x.Message
 	}
@@ -932,10 +3628,11 @@ var File_pkg_gfs_gfs_proto protoreflect.FileDescriptor
 
 const file_pkg_gfs_gfs_proto_rawDesc = "" +
 	"\n" +
-	"\x11pkg/gfs/gfs.proto\x12\x03gfs\"J\n" +
+	"\x11pkg/gfs/gfs.proto\x12\x03gfs\"d\n" +
 	"\x11StoreChunkRequest\x12!\n" +
 	"\fchunk_handle\x18\x01 \x01(\tR\vchunkHandle\x12\x12\n" +
-	"\x04data\x18\x02 \x01(\fR\x04data\"H\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\x04R\aversion\"H\n" +
 	"\x12StoreChunkResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\"9\n" +
@@ -949,10 +3646,24 @@ const file_pkg_gfs_gfs_proto_rawDesc = "" +
 	"\fchunk_handle\x18\x01 \x01(\tR\vchunkHandle\"I\n" +
 	"\x13DeleteChunkResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"C\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"d\n" +
+	"\x11WriteChunkRequest\x12!\n" +
+	"\fchunk_handle\x18\x01 \x01(\tR\vchunkHandle\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\x04R\aversion\"g\n" +
+	"\x10ReadChunkRequest\x12!\n" +
+	"\fchunk_handle\x18\x01 \x01(\tR\vchunkHandle\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x03R\x06offset\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\x04R\aversion\"Z\n" +
+	"\x11ReadChunkResponse\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x12\"\n" +
+	"\n" +
+	"chunk_size\x18\x02 \x01(\x03H\x00R\tchunkSize\x88\x01\x01B\r\n" +
+	"\v_chunk_size\"r\n" +
 	"\x11UploadFileRequest\x12\x1a\n" +
 	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x12\n" +
-	"\x04data\x18\x02 \x01(\fR\x04data\"H\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\x12-\n" +
+	"\x12replication_factor\x18\x03 \x01(\x05R\x11replicationFactor\"H\n" +
 	"\x12UploadFileResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\"1\n" +
@@ -961,29 +3672,225 @@ const file_pkg_gfs_gfs_proto_rawDesc = "" +
 	"\x14DownloadFileResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x12\n" +
 	"\x04data\x18\x02 \x01(\fR\x04data\x12\x18\n" +
-	"\amessage\x18\x03 \x01(\tR\amessage\"&\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"x\n" +
+	"\x17UploadFileStreamRequest\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\x12-\n" +
+	"\x12replication_factor\x18\x03 \x01(\x05R\x11replicationFactor\"0\n" +
+	"\x1aDownloadFileStreamResponse\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\"U\n" +
 	"\x10ListFilesRequest\x12\x12\n" +
-	"\x04path\x18\x01 \x01(\tR\x04path\"]\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12-\n" +
+	"\x12include_attributes\x18\x02 \x01(\bR\x11includeAttributes\"\xad\x01\n" +
 	"\x11ListFilesResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
 	"\x05files\x18\x02 \x03(\tR\x05files\x12\x18\n" +
-	"\amessage\x18\x03 \x01(\tR\amessage\"/\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12 \n" +
+	"\vdirectories\x18\x04 \x03(\tR\vdirectories\x12,\n" +
+	"\n" +
+	"file_infos\x18\x05 \x03(\v2\r.gfs.FileInfoR\tfileInfos\"}\n" +
+	"\tChunkInfo\x12!\n" +
+	"\fchunk_handle\x18\x01 \x01(\tR\vchunkHandle\x123\n" +
+	"\x15chunkserver_addresses\x18\x02 \x03(\tR\x14chunkserverAddresses\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\x04R\aversion\"\xa9\x02\n" +
+	"\bFileInfo\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12!\n" +
+	"\fis_directory\x18\x02 \x01(\bR\visDirectory\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x03R\x04size\x12\x1f\n" +
+	"\vchunk_count\x18\x04 \x01(\x05R\n" +
+	"chunkCount\x12&\n" +
+	"\x06chunks\x18\x05 \x03(\v2\x0e.gfs.ChunkInfoR\x06chunks\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\x03R\tcreatedAt\x12\x1f\n" +
+	"\vmodified_at\x18\a \x01(\x03R\n" +
+	"modifiedAt\x12-\n" +
+	"\x12replication_factor\x18\b \x01(\x05R\x11replicationFactor\x12\x1a\n" +
+	"\bchecksum\x18\t \x01(\tR\bchecksum\"!\n" +
+	"\vStatRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\"e\n" +
+	"\fStatResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12!\n" +
+	"\x04info\x18\x03 \x01(\v2\r.gfs.FileInfoR\x04info\"M\n" +
 	"\x11DeleteFileRequest\x12\x1a\n" +
-	"\bfilename\x18\x01 \x01(\tR\bfilename\"H\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x1c\n" +
+	"\trecursive\x18\x02 \x01(\bR\trecursive\"H\n" +
 	"\x12DeleteFileResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"<\n" +
+	"\fMkdirRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x18\n" +
+	"\aparents\x18\x02 \x01(\bR\aparents\"C\n" +
+	"\rMkdirResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\"\n" +
+	"\fRmdirRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\"C\n" +
+	"\rRmdirResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"g\n" +
+	"\rRenameRequest\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12 \n" +
+	"\vdestination\x18\x02 \x01(\tR\vdestination\x12\x1c\n" +
+	"\toverwrite\x18\x03 \x01(\bR\toverwrite\"D\n" +
+	"\x0eRenameResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\"W\n" +
 	"\x18GetChunkLocationsRequest\x12\x1a\n" +
 	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x1f\n" +
 	"\vchunk_index\x18\x02 \x01(\x05R\n" +
-	"chunkIndex\"s\n" +
+	"chunkIndex\"\xcb\x01\n" +
 	"\x19GetChunkLocationsResponse\x123\n" +
 	"\x15chunkserver_addresses\x18\x01 \x03(\tR\x14chunkserverAddresses\x12!\n" +
-	"\fchunk_handle\x18\x02 \x01(\tR\vchunkHandle\"9\n" +
+	"\fchunk_handle\x18\x02 \x01(\tR\vchunkHandle\x12\x1f\n" +
+	"\vchunk_count\x18\x03 \x01(\x05R\n" +
+	"chunkCount\x12\x1b\n" +
+	"\tfile_size\x18\x04 \x01(\x03R\bfileSize\x12\x18\n" +
+	"\aversion\x18\x05 \x01(\x04R\aversion\"\x82\x01\n" +
+	"\x14AllocateChunkRequest\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x1f\n" +
+	"\vchunk_index\x18\x02 \x01(\x05R\n" +
+	"chunkIndex\x12-\n" +
+	"\x12replication_factor\x18\x03 \x01(\x05R\x11replicationFactor\"\xdc\x01\n" +
+	"\x15AllocateChunkResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12!\n" +
+	"\fchunk_handle\x18\x03 \x01(\tR\vchunkHandle\x123\n" +
+	"\x15chunkserver_addresses\x18\x04 \x03(\tR\x14chunkserverAddresses\x12\x1d\n" +
+	"\n" +
+	"chunk_size\x18\x05 \x01(\x03R\tchunkSize\x12\x18\n" +
+	"\aversion\x18\x06 \x01(\x04R\aversion\"\x96\x01\n" +
+	"\x0eCommittedChunk\x12!\n" +
+	"\fchunk_handle\x18\x01 \x01(\tR\vchunkHandle\x123\n" +
+	"\x15chunkserver_addresses\x18\x02 \x03(\tR\x14chunkserverAddresses\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x03R\x04size\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\x04R\aversion\"\xa7\x01\n" +
+	"\x11CommitFileRequest\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12+\n" +
+	"\x06chunks\x18\x02 \x03(\v2\x13.gfs.CommittedChunkR\x06chunks\x12\x1a\n" +
+	"\bchecksum\x18\x03 \x01(\tR\bchecksum\x12-\n" +
+	"\x12replication_factor\x18\x04 \x01(\x05R\x11replicationFactor\"H\n" +
+	"\x12CommitFileResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\x8f\x02\n" +
 	"\x10HeartbeatRequest\x12%\n" +
-	"\x0echunkserver_id\x18\x01 \x01(\tR\rchunkserverId\"-\n" +
+	"\x0echunkserver_id\x18\x01 \x01(\tR\rchunkserverId\x12+\n" +
+	"\x05stats\x18\x02 \x01(\v2\x15.gfs.ChunkserverStatsR\x05stats\x12\x18\n" +
+	"\aaddress\x18\x03 \x01(\tR\aaddress\x12;\n" +
+	"\x0fcommand_results\x18\x04 \x03(\v2\x12.gfs.CommandResultR\x0ecommandResults\x12)\n" +
+	"\btopology\x18\x05 \x01(\v2\r.gfs.TopologyR\btopology\x12%\n" +
+	"\x0ecorrupt_chunks\x18\x06 \x03(\tR\rcorruptChunks\"F\n" +
+	"\bTopology\x12\x12\n" +
+	"\x04zone\x18\x01 \x01(\tR\x04zone\x12\x12\n" +
+	"\x04rack\x18\x02 \x01(\tR\x04rack\x12\x12\n" +
+	"\x04host\x18\x03 \x01(\tR\x04host\"\x83\x02\n" +
+	"\x10ChunkserverStats\x12\x1f\n" +
+	"\vchunk_count\x18\x01 \x01(\x05R\n" +
+	"chunkCount\x12\x1d\n" +
+	"\n" +
+	"used_bytes\x18\x02 \x01(\x03R\tusedBytes\x12\x1d\n" +
+	"\n" +
+	"free_bytes\x18\x03 \x01(\x03R\tfreeBytes\x12+\n" +
+	"\x11active_operations\x18\x04 \x01(\x05R\x10activeOperations\x12\x1a\n" +
+	"\bdraining\x18\x05 \x01(\bR\bdraining\x12\x1f\n" +
+	"\vtotal_bytes\x18\x06 \x01(\x03R\n" +
+	"totalBytes\x12&\n" +
+	"\x05scrub\x18\a \x01(\v2\x10.gfs.ScrubStatusR\x05scrub\"\x81\x02\n" +
+	"\vScrubStatus\x12\x18\n" +
+	"\arunning\x18\x01 \x01(\bR\arunning\x12'\n" +
+	"\x0fscrubbed_chunks\x18\x02 \x01(\x05R\x0escrubbedChunks\x12!\n" +
+	"\ftotal_chunks\x18\x03 \x01(\x05R\vtotalChunks\x12%\n" +
+	"\x0escrubbed_bytes\x18\x04 \x01(\x03R\rscrubbedBytes\x12\x1f\n" +
+	"\vtotal_bytes\x18\x05 \x01(\x03R\n" +
+	"totalBytes\x12\x1d\n" +
+	"\n" +
+	"last_scrub\x18\x06 \x01(\x03R\tlastScrub\x12%\n" +
+	"\x0ecorrupt_chunks\x18\a \x01(\x03R\rcorruptChunks\"b\n" +
 	"\x11HeartbeatResponse\x12\x18\n" +
-	"\amessage\x18\x01 \x01(\tR\amessage2\x99\x03\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x123\n" +
+	"\bcommands\x18\x02 \x03(\v2\x17.gfs.ChunkserverCommandR\bcommands\"\xbc\x02\n" +
+	"\x12ChunkserverCommand\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x120\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x1c.gfs.ChunkserverCommand.TypeR\x04type\x12#\n" +
+	"\rchunk_handles\x18\x03 \x03(\tR\fchunkHandles\x12!\n" +
+	"\fchunk_handle\x18\x04 \x01(\tR\vchunkHandle\x12\x18\n" +
+	"\aversion\x18\x05 \x01(\x04R\aversion\x12%\n" +
+	"\x0esource_address\x18\x06 \x01(\tR\rsourceAddress\x12\x14\n" +
+	"\x05drain\x18\a \x01(\bR\x05drain\"E\n" +
+	"\x04Type\x12\v\n" +
+	"\aUNKNOWN\x10\x00\x12\n" +
+	"\n" +
+	"\x06DELETE\x10\x01\x12\r\n" +
+	"\tREPLICATE\x10\x02\x12\n" +
+	"\n" +
+	"\x06REPORT\x10\x03\x12\t\n" +
+	"\x05DRAIN\x10\x04\"v\n" +
+	"\rCommandResult\x12\x1d\n" +
+	"\n" +
+	"command_id\x18\x01 \x01(\x04R\tcommandId\x12\x18\n" +
+	"\asuccess\x18\x02 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\x03R\x04size\"^\n" +
+	"\vChunkReport\x12!\n" +
+	"\fchunk_handle\x18\x01 \x01(\tR\vchunkHandle\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\x04R\aversion\"\xab\x01\n" +
+	"\x13ReportChunksRequest\x12%\n" +
+	"\x0echunkserver_id\x18\x01 \x01(\tR\rchunkserverId\x12(\n" +
+	"\x06chunks\x18\x02 \x03(\v2\x10.gfs.ChunkReportR\x06chunks\x12\x18\n" +
+	"\aaddress\x18\x03 \x01(\tR\aaddress\x12)\n" +
+	"\btopology\x18\x04 \x01(\v2\r.gfs.TopologyR\btopology\"J\n" +
+	"\x14ReportChunksResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\x19\n" +
+	"\x17ListChunkserversRequest\"\xbe\x02\n" +
+	"\x11ChunkserverStatus\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\tR\aaddress\x12\x1b\n" +
+	"\tlast_seen\x18\x02 \x01(\x03R\blastSeen\x12\x18\n" +
+	"\ahealthy\x18\x03 \x01(\bR\ahealthy\x12+\n" +
+	"\x05stats\x18\x04 \x01(\v2\x15.gfs.ChunkserverStatsR\x05stats\x12\x0e\n" +
+	"\x02id\x18\x05 \x01(\tR\x02id\x12)\n" +
+	"\btopology\x18\x06 \x01(\v2\r.gfs.TopologyR\btopology\x12\x12\n" +
+	"\x04full\x18\a \x01(\bR\x04full\x12\x1a\n" +
+	"\bdraining\x18\b \x01(\bR\bdraining\x12&\n" +
+	"\x0fchunks_to_drain\x18\t \x01(\x05R\rchunksToDrain\x12\x18\n" +
+	"\adrained\x18\n" +
+	" \x01(\bR\adrained\"\xf5\x01\n" +
+	"\x18ListChunkserversResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12:\n" +
+	"\fchunkservers\x18\x03 \x03(\v2\x16.gfs.ChunkserverStatusR\fchunkservers\x128\n" +
+	"\vreplication\x18\x04 \x01(\v2\x16.gfs.ReplicationStatusR\vreplication\x12/\n" +
+	"\bbalancer\x18\x05 \x01(\v2\x13.gfs.BalancerStatusR\bbalancer\"\x88\x01\n" +
+	"\x11ReplicationStatus\x12#\n" +
+	"\rqueued_chunks\x18\x01 \x01(\x05R\fqueuedChunks\x12)\n" +
+	"\x10missing_replicas\x18\x02 \x01(\x05R\x0fmissingReplicas\x12#\n" +
+	"\ractive_copies\x18\x03 \x01(\x05R\factiveCopies\"Z\n" +
+	"\x15SetReplicationRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12-\n" +
+	"\x12replication_factor\x18\x02 \x01(\x05R\x11replicationFactor\"L\n" +
+	"\x16SetReplicationResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\".\n" +
+	"\x12SetBalancerRequest\x12\x18\n" +
+	"\arunning\x18\x01 \x01(\bR\arunning\"v\n" +
+	"\x13SetBalancerResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12+\n" +
+	"\x06status\x18\x03 \x01(\v2\x13.gfs.BalancerStatusR\x06status\"\xa5\x01\n" +
+	"\x0eBalancerStatus\x12\x18\n" +
+	"\arunning\x18\x01 \x01(\bR\arunning\x12\x12\n" +
+	"\x04skew\x18\x02 \x01(\x01R\x04skew\x12!\n" +
+	"\factive_moves\x18\x03 \x01(\x05R\vactiveMoves\x12!\n" +
+	"\fmoved_chunks\x18\x04 \x01(\x03R\vmovedChunks\x12\x1f\n" +
+	"\vmoved_bytes\x18\x05 \x01(\x03R\n" +
+	"movedBytes\"V\n" +
+	"\x17DrainChunkserverRequest\x12%\n" +
+	"\x0echunkserver_id\x18\x01 \x01(\tR\rchunkserverId\x12\x14\n" +
+	"\x05drain\x18\x02 \x01(\bR\x05drain\"N\n" +
+	"\x18DrainChunkserverResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage2\xf4\t\n" +
 	"\x06Master\x12<\n" +
 	"\tHeartbeat\x12\x15.gfs.HeartbeatRequest\x1a\x16.gfs.HeartbeatResponse\"\x00\x12=\n" +
 	"\n" +
@@ -992,12 +3899,29 @@ const file_pkg_gfs_gfs_proto_rawDesc = "" +
 	"\tListFiles\x12\x15.gfs.ListFilesRequest\x1a\x16.gfs.ListFilesResponse\x12=\n" +
 	"\n" +
 	"DeleteFile\x12\x16.gfs.DeleteFileRequest\x1a\x17.gfs.DeleteFileResponse\x12R\n" +
-	"\x11GetChunkLocations\x12\x1d.gfs.GetChunkLocationsRequest\x1a\x1e.gfs.GetChunkLocationsResponse2\xd6\x01\n" +
+	"\x11GetChunkLocations\x12\x1d.gfs.GetChunkLocationsRequest\x1a\x1e.gfs.GetChunkLocationsResponse\x12K\n" +
+	"\x10UploadFileStream\x12\x1c.gfs.UploadFileStreamRequest\x1a\x17.gfs.UploadFileResponse(\x01\x12Q\n" +
+	"\x12DownloadFileStream\x12\x18.gfs.DownloadFileRequest\x1a\x1f.gfs.DownloadFileStreamResponse0\x01\x12F\n" +
+	"\rAllocateChunk\x12\x19.gfs.AllocateChunkRequest\x1a\x1a.gfs.AllocateChunkResponse\x12=\n" +
+	"\n" +
+	"CommitFile\x12\x16.gfs.CommitFileRequest\x1a\x17.gfs.CommitFileResponse\x12C\n" +
+	"\fReportChunks\x12\x18.gfs.ReportChunksRequest\x1a\x19.gfs.ReportChunksResponse\x12.\n" +
+	"\x05Mkdir\x12\x11.gfs.MkdirRequest\x1a\x12.gfs.MkdirResponse\x12.\n" +
+	"\x05Rmdir\x12\x11.gfs.RmdirRequest\x1a\x12.gfs.RmdirResponse\x121\n" +
+	"\x06Rename\x12\x12.gfs.RenameRequest\x1a\x13.gfs.RenameResponse\x12+\n" +
+	"\x04Stat\x12\x10.gfs.StatRequest\x1a\x11.gfs.StatResponse\x12O\n" +
+	"\x10ListChunkservers\x12\x1c.gfs.ListChunkserversRequest\x1a\x1d.gfs.ListChunkserversResponse\x12I\n" +
+	"\x0eSetReplication\x12\x1a.gfs.SetReplicationRequest\x1a\x1b.gfs.SetReplicationResponse\x12@\n" +
+	"\vSetBalancer\x12\x17.gfs.SetBalancerRequest\x1a\x18.gfs.SetBalancerResponse\x12O\n" +
+	"\x10DrainChunkserver\x12\x1c.gfs.DrainChunkserverRequest\x1a\x1d.gfs.DrainChunkserverResponse2\xd5\x02\n" +
 	"\vChunkserver\x12=\n" +
 	"\n" +
 	"StoreChunk\x12\x16.gfs.StoreChunkRequest\x1a\x17.gfs.StoreChunkResponse\x12F\n" +
 	"\rRetrieveChunk\x12\x19.gfs.RetrieveChunkRequest\x1a\x1a.gfs.RetrieveChunkResponse\x12@\n" +
-	"\vDeleteChunk\x12\x17.gfs.DeleteChunkRequest\x1a\x18.gfs.DeleteChunkResponseB#Z!github.com/sdudhani/godfs/pkg/gfsb\x06proto3"
+	"\vDeleteChunk\x12\x17.gfs.DeleteChunkRequest\x1a\x18.gfs.DeleteChunkResponse\x12?\n" +
+	"\n" +
+	"WriteChunk\x12\x16.gfs.WriteChunkRequest\x1a\x17.gfs.StoreChunkResponse(\x01\x12<\n" +
+	"\tReadChunk\x12\x15.gfs.ReadChunkRequest\x1a\x16.gfs.ReadChunkResponse0\x01B#Z!github.com/sdudhani/godfs/pkg/gfsb\x06proto3"
 
 var (
 	file_pkg_gfs_gfs_proto_rawDescOnce sync.Once
@@ -1011,51 +3935,140 @@ func file_pkg_gfs_gfs_proto_rawDescGZIP() []byte {
 	return file_pkg_gfs_gfs_proto_rawDescData
 }
 
-var file_pkg_gfs_gfs_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
+var file_pkg_gfs_gfs_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_pkg_gfs_gfs_proto_msgTypes = make([]protoimpl.MessageInfo, 57)
 var file_pkg_gfs_gfs_proto_goTypes = []any{
-	(*StoreChunkRequest)(nil),         // 0: gfs.StoreChunkRequest
-	(*StoreChunkResponse)(nil),        // 1: gfs.StoreChunkResponse
-	(*RetrieveChunkRequest)(nil),      // 2: gfs.RetrieveChunkRequest
-	(*RetrieveChunkResponse)(nil),     // 3: gfs.RetrieveChunkResponse
-	(*DeleteChunkRequest)(nil),        // 4: gfs.DeleteChunkRequest
-	(*DeleteChunkResponse)(nil),       // 5: gfs.DeleteChunkResponse
-	(*UploadFileRequest)(nil),         // 6: gfs.UploadFileRequest
-	(*UploadFileResponse)(nil),        // 7: gfs.UploadFileResponse
-	(*DownloadFileRequest)(nil),       // 8: gfs.DownloadFileRequest
-	(*DownloadFileResponse)(nil),      // 9: gfs.DownloadFileResponse
-	(*ListFilesRequest)(nil),          // 10: gfs.ListFilesRequest
-	(*ListFilesResponse)(nil),         // 11: gfs.ListFilesResponse
-	(*DeleteFileRequest)(nil),         // 12: gfs.DeleteFileRequest
-	(*DeleteFileResponse)(nil),        // 13: gfs.DeleteFileResponse
-	(*GetChunkLocationsRequest)(nil),  // 14: gfs.GetChunkLocationsRequest
-	(*GetChunkLocationsResponse)(nil), // 15: gfs.GetChunkLocationsResponse
-	(*HeartbeatRequest)(nil),          // 16: gfs.HeartbeatRequest
-	(*HeartbeatResponse)(nil),         // 17: gfs.HeartbeatResponse
+	(ChunkserverCommand_Type)(0),       // 0: gfs.ChunkserverCommand.Type
+	(*StoreChunkRequest)(nil),          // 1: gfs.StoreChunkRequest
+	(*StoreChunkResponse)(nil),         // 2: gfs.StoreChunkResponse
+	(*RetrieveChunkRequest)(nil),       // 3: gfs.RetrieveChunkRequest
+	(*RetrieveChunkResponse)(nil),      // 4: gfs.RetrieveChunkResponse
+	(*DeleteChunkRequest)(nil),         // 5: gfs.DeleteChunkRequest
+	(*DeleteChunkResponse)(nil),        // 6: gfs.DeleteChunkResponse
+	(*WriteChunkRequest)(nil),          // 7: gfs.WriteChunkRequest
+	(*ReadChunkRequest)(nil),           // 8: gfs.ReadChunkRequest
+	(*ReadChunkResponse)(nil),          // 9: gfs.ReadChunkResponse
+	(*UploadFileRequest)(nil),          // 10: gfs.UploadFileRequest
+	(*UploadFileResponse)(nil),         // 11: gfs.UploadFileResponse
+	(*DownloadFileRequest)(nil),        // 12: gfs.DownloadFileRequest
+	(*DownloadFileResponse)(nil),       // 13: gfs.DownloadFileResponse
+	(*UploadFileStreamRequest)(nil),    // 14: gfs.UploadFileStreamRequest
+	(*DownloadFileStreamResponse)(nil), // 15: gfs.DownloadFileStreamResponse
+	(*ListFilesRequest)(nil),           // 16: gfs.ListFilesRequest
+	(*ListFilesResponse)(nil),          // 17: gfs.ListFilesResponse
+	(*ChunkInfo)(nil),                  // 18: gfs.ChunkInfo
+	(*FileInfo)(nil),                   // 19: gfs.FileInfo
+	(*StatRequest)(nil),                // 20: gfs.StatRequest
+	(*StatResponse)(nil),               // 21: gfs.StatResponse
+	(*DeleteFileRequest)(nil),          // 22: gfs.DeleteFileRequest
+	(*DeleteFileResponse)(nil),         // 23: gfs.DeleteFileResponse
+	(*MkdirRequest)(nil),               // 24: gfs.MkdirRequest
+	(*MkdirResponse)(nil),              // 25: gfs.MkdirResponse
+	(*RmdirRequest)(nil),               // 26: gfs.RmdirRequest
+	(*RmdirResponse)(nil),              // 27: gfs.RmdirResponse
+	(*RenameRequest)(nil),              // 28: gfs.RenameRequest
+	(*RenameResponse)(nil),             // 29: gfs.RenameResponse
+	(*GetChunkLocationsRequest)(nil),   // 30: gfs.GetChunkLocationsRequest
+	(*GetChunkLocationsResponse)(nil),  // 31: gfs.GetChunkLocationsResponse
+	(*AllocateChunkRequest)(nil),       // 32: gfs.AllocateChunkRequest
+	(*AllocateChunkResponse)(nil),      // 33: gfs.AllocateChunkResponse
+	(*CommittedChunk)(nil),             // 34: gfs.CommittedChunk
+	(*CommitFileRequest)(nil),          // 35: gfs.CommitFileRequest
+	(*CommitFileResponse)(nil),         // 36: gfs.CommitFileResponse
+	(*HeartbeatRequest)(nil),           // 37: gfs.HeartbeatRequest
+	(*Topology)(nil),                   // 38: gfs.Topology
+	(*ChunkserverStats)(nil),           // 39: gfs.ChunkserverStats
+	(*ScrubStatus)(nil),                // 40: gfs.ScrubStatus
+	(*HeartbeatResponse)(nil),          // 41: gfs.HeartbeatResponse
+	(*ChunkserverCommand)(nil),         // 42: gfs.ChunkserverCommand
+	(*CommandResult)(nil),              // 43: gfs.CommandResult
+	(*ChunkReport)(nil),                // 44: gfs.ChunkReport
+	(*ReportChunksRequest)(nil),        // 45: gfs.ReportChunksRequest
+	(*ReportChunksResponse)(nil),       // 46: gfs.ReportChunksResponse
+	(*ListChunkserversRequest)(nil),    // 47: gfs.ListChunkserversRequest
+	(*ChunkserverStatus)(nil),          // 48: gfs.ChunkserverStatus
+	(*ListChunkserversResponse)(nil),   // 49: gfs.ListChunkserversResponse
+	(*ReplicationStatus)(nil),          // 50: gfs.ReplicationStatus
+	(*SetReplicationRequest)(nil),      // 51: gfs.SetReplicationRequest
+	(*SetReplicationResponse)(nil),     // 52: gfs.SetReplicationResponse
+	(*SetBalancerRequest)(nil),         // 53: gfs.SetBalancerRequest
+	(*SetBalancerResponse)(nil),        // 54: gfs.SetBalancerResponse
+	(*BalancerStatus)(nil),             // 55: gfs.BalancerStatus
+	(*DrainChunkserverRequest)(nil),    // 56: gfs.DrainChunkserverRequest
+	(*DrainChunkserverResponse)(nil),   // 57: gfs.DrainChunkserverResponse
 }
 var file_pkg_gfs_gfs_proto_depIdxs = []int32{
-	16, // 0: gfs.Master.Heartbeat:input_type -> gfs.HeartbeatRequest
-	6,  // 1: gfs.Master.UploadFile:input_type -> gfs.UploadFileRequest
-	8,  // 2: gfs.Master.DownloadFile:input_type -> gfs.DownloadFileRequest
-	10, // 3: gfs.Master.ListFiles:input_type -> gfs.ListFilesRequest
-	12, // 4: gfs.Master.DeleteFile:input_type -> gfs.DeleteFileRequest
-	14, // 5: gfs.Master.GetChunkLocations:input_type -> gfs.GetChunkLocationsRequest
-	0,  // 6: gfs.Chunkserver.StoreChunk:input_type -> gfs.StoreChunkRequest
-	2,  // 7: gfs.Chunkserver.RetrieveChunk:input_type -> gfs.RetrieveChunkRequest
-	4,  // 8: gfs.Chunkserver.DeleteChunk:input_type -> gfs.DeleteChunkRequest
-	17, // 9: gfs.Master.Heartbeat:output_type -> gfs.HeartbeatResponse
-	7,  // 10: gfs.Master.UploadFile:output_type -> gfs.UploadFileResponse
-	9,  // 11: gfs.Master.DownloadFile:output_type -> gfs.DownloadFileResponse
-	11, // 12: gfs.Master.ListFiles:output_type -> gfs.ListFilesResponse
-	13, // 13: gfs.Master.DeleteFile:output_type -> gfs.DeleteFileResponse
-	15, // 14: gfs.Master.GetChunkLocations:output_type -> gfs.GetChunkLocationsResponse
-	1,  // 15: gfs.Chunkserver.StoreChunk:output_type -> gfs.StoreChunkResponse
-	3,  // 16: gfs.Chunkserver.RetrieveChunk:output_type -> gfs.RetrieveChunkResponse
-	5,  // 17: gfs.Chunkserver.DeleteChunk:output_type -> gfs.DeleteChunkResponse
-	9,  // [9:18] is the sub-list for method output_type
-	0,  // [0:9] is the sub-list for method input_type
-	0,  // [0:0] is the sub-list for extension type_name
-	0,  // [0:0] is the sub-list for extension extendee
-	0,  // [0:0] is the sub-list for field type_name
+	19, // 0: gfs.ListFilesResponse.file_infos:type_name -> gfs.FileInfo
+	18, // 1: gfs.FileInfo.chunks:type_name -> gfs.ChunkInfo
+	19, // 2: gfs.StatResponse.info:type_name -> gfs.FileInfo
+	34, // 3: gfs.CommitFileRequest.chunks:type_name -> gfs.CommittedChunk
+	39, // 4: gfs.HeartbeatRequest.stats:type_name -> gfs.ChunkserverStats
+	43, // 5: gfs.HeartbeatRequest.command_results:type_name -> gfs.CommandResult
+	38, // 6: gfs.HeartbeatRequest.topology:type_name -> gfs.Topology
+	40, // 7: gfs.ChunkserverStats.scrub:type_name -> gfs.ScrubStatus
+	42, // 8: gfs.HeartbeatResponse.commands:type_name -> gfs.ChunkserverCommand
+	0,  // 9: gfs.ChunkserverCommand.type:type_name -> gfs.ChunkserverCommand.Type
+	44, // 10: gfs.ReportChunksRequest.chunks:type_name -> gfs.ChunkReport
+	38, // 11: gfs.ReportChunksRequest.topology:type_name -> gfs.Topology
+	39, // 12: gfs.ChunkserverStatus.stats:type_name -> gfs.ChunkserverStats
+	38, // 13: gfs.ChunkserverStatus.topology:type_name -> gfs.Topology
+	48, // 14: gfs.ListChunkserversResponse.chunkservers:type_name -> gfs.ChunkserverStatus
+	50, // 15: gfs.ListChunkserversResponse.replication:type_name -> gfs.ReplicationStatus
+	55, // 16: gfs.ListChunkserversResponse.balancer:type_name -> gfs.BalancerStatus
+	55, // 17: gfs.SetBalancerResponse.status:type_name -> gfs.BalancerStatus
+	37, // 18: gfs.Master.Heartbeat:input_type -> gfs.HeartbeatRequest
+	10, // 19: gfs.Master.UploadFile:input_type -> gfs.UploadFileRequest
+	12, // 20: gfs.Master.DownloadFile:input_type -> gfs.DownloadFileRequest
+	16, // 21: gfs.Master.ListFiles:input_type -> gfs.ListFilesRequest
+	22, // 22: gfs.Master.DeleteFile:input_type -> gfs.DeleteFileRequest
+	30, // 23: gfs.Master.GetChunkLocations:input_type -> gfs.GetChunkLocationsRequest
+	14, // 24: gfs.Master.UploadFileStream:input_type -> gfs.UploadFileStreamRequest
+	12, // 25: gfs.Master.DownloadFileStream:input_type -> gfs.DownloadFileRequest
+	32, // 26: gfs.Master.AllocateChunk:input_type -> gfs.AllocateChunkRequest
+	35, // 27: gfs.Master.CommitFile:input_type -> gfs.CommitFileRequest
+	45, // 28: gfs.Master.ReportChunks:input_type -> gfs.ReportChunksRequest
+	24, // 29: gfs.Master.Mkdir:input_type -> gfs.MkdirRequest
+	26, // 30: gfs.Master.Rmdir:input_type -> gfs.RmdirRequest
+	28, // 31: gfs.Master.Rename:input_type -> gfs.RenameRequest
+	20, // 32: gfs.Master.Stat:input_type -> gfs.StatRequest
+	47, // 33: gfs.Master.ListChunkservers:input_type -> gfs.ListChunkserversRequest
+	51, // 34: gfs.Master.SetReplication:input_type -> gfs.SetReplicationRequest
+	53, // 35: gfs.Master.SetBalancer:input_type -> gfs.SetBalancerRequest
+	56, // 36: gfs.Master.DrainChunkserver:input_type -> gfs.DrainChunkserverRequest
+	1,  // 37: gfs.Chunkserver.StoreChunk:input_type -> gfs.StoreChunkRequest
+	3,  // 38: gfs.Chunkserver.RetrieveChunk:input_type -> gfs.RetrieveChunkRequest
+	5,  // 39: gfs.Chunkserver.DeleteChunk:input_type -> gfs.DeleteChunkRequest
+	7,  // 40: gfs.Chunkserver.WriteChunk:input_type -> gfs.WriteChunkRequest
+	8,  // 41: gfs.Chunkserver.ReadChunk:input_type -> gfs.ReadChunkRequest
+	41, // 42: gfs.Master.Heartbeat:output_type -> gfs.HeartbeatResponse
+	11, // 43: gfs.Master.UploadFile:output_type -> gfs.UploadFileResponse
+	13, // 44: gfs.Master.DownloadFile:output_type -> gfs.DownloadFileResponse
+	17, // 45: gfs.Master.ListFiles:output_type -> gfs.ListFilesResponse
+	23, // 46: gfs.Master.DeleteFile:output_type -> gfs.DeleteFileResponse
+	31, // 47: gfs.Master.GetChunkLocations:output_type -> gfs.GetChunkLocationsResponse
+	11, // 48: gfs.Master.UploadFileStream:output_type -> gfs.UploadFileResponse
+	15, // 49: gfs.Master.DownloadFileStream:output_type -> gfs.DownloadFileStreamResponse
+	33, // 50: gfs.Master.AllocateChunk:output_type -> gfs.AllocateChunkResponse
+	36, // 51: gfs.Master.CommitFile:output_type -> gfs.CommitFileResponse
+	46, // 52: gfs.Master.ReportChunks:output_type -> gfs.ReportChunksResponse
+	25, // 53: gfs.Master.Mkdir:output_type -> gfs.MkdirResponse
+	27, // 54: gfs.Master.Rmdir:output_type -> gfs.RmdirResponse
+	29, // 55: gfs.Master.Rename:output_type -> gfs.RenameResponse
+	21, // 56: gfs.Master.Stat:output_type -> gfs.StatResponse
+	49, // 57: gfs.Master.ListChunkservers:output_type -> gfs.ListChunkserversResponse
+	52, // 58: gfs.Master.SetReplication:output_type -> gfs.SetReplicationResponse
+	54, // 59: gfs.Master.SetBalancer:output_type -> gfs.SetBalancerResponse
+	57, // 60: gfs.Master.DrainChunkserver:output_type -> gfs.DrainChunkserverResponse
+	2,  // 61: gfs.Chunkserver.StoreChunk:output_type -> gfs.StoreChunkResponse
+	4,  // 62: gfs.Chunkserver.RetrieveChunk:output_type -> gfs.RetrieveChunkResponse
+	6,  // 63: gfs.Chunkserver.DeleteChunk:output_type -> gfs.DeleteChunkResponse
+	2,  // 64: gfs.Chunkserver.WriteChunk:output_type -> gfs.StoreChunkResponse
+	9,  // 65: gfs.Chunkserver.ReadChunk:output_type -> gfs.ReadChunkResponse
+	42, // [42:66] is the sub-list for method output_type
+	18, // [18:42] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
 }
 
 func init() { file_pkg_gfs_gfs_proto_init() }
@@ -1063,18 +4076,20 @@ func file_pkg_gfs_gfs_proto_init() {
 	if File_pkg_gfs_gfs_proto != nil {
 		return
 	}
+	file_pkg_gfs_gfs_proto_msgTypes[8].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pkg_gfs_gfs_proto_rawDesc), len(file_pkg_gfs_gfs_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   18,
+			NumEnums:      1,
+			NumMessages:   57,
 			NumExtensions: 0,
 			NumServices:   2,
 		},
 		GoTypes:           file_pkg_gfs_gfs_proto_goTypes,
 		DependencyIndexes: file_pkg_gfs_gfs_proto_depIdxs,
+		EnumInfos:         file_pkg_gfs_gfs_proto_enumTypes,
 		MessageInfos:      file_pkg_gfs_gfs_proto_msgTypes,
 	}.Build()
 	File_pkg_gfs_gfs_proto = out.File
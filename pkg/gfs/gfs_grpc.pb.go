@@ -21,12 +21,25 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Master_Heartbeat_FullMethodName         = "/gfs.Master/Heartbeat"
-	Master_UploadFile_FullMethodName        = "/gfs.Master/UploadFile"
-	Master_DownloadFile_FullMethodName      = "/gfs.Master/DownloadFile"
-	Master_ListFiles_FullMethodName         = "/gfs.Master/ListFiles"
-	Master_DeleteFile_FullMethodName        = "/gfs.Master/DeleteFile"
-	Master_GetChunkLocations_FullMethodName = "/gfs.Master/GetChunkLocations"
+	Master_Heartbeat_FullMethodName          = "/gfs.Master/Heartbeat"
+	Master_UploadFile_FullMethodName         = "/gfs.Master/UploadFile"
+	Master_DownloadFile_FullMethodName       = "/gfs.Master/DownloadFile"
+	Master_ListFiles_FullMethodName          = "/gfs.Master/ListFiles"
+	Master_DeleteFile_FullMethodName         = "/gfs.Master/DeleteFile"
+	Master_GetChunkLocations_FullMethodName  = "/gfs.Master/GetChunkLocations"
+	Master_UploadFileStream_FullMethodName   = "/gfs.Master/UploadFileStream"
+	Master_DownloadFileStream_FullMethodName = "/gfs.Master/DownloadFileStream"
+	Master_AllocateChunk_FullMethodName      = "/gfs.Master/AllocateChunk"
+	Master_CommitFile_FullMethodName         = "/gfs.Master/CommitFile"
+	Master_ReportChunks_FullMethodName       = "/gfs.Master/ReportChunks"
+	Master_Mkdir_FullMethodName              = "/gfs.Master/Mkdir"
+	Master_Rmdir_FullMethodName              = "/gfs.Master/Rmdir"
+	Master_Rename_FullMethodName             = "/gfs.Master/Rename"
+	Master_Stat_FullMethodName               = "/gfs.Master/Stat"
+	Master_ListChunkservers_FullMethodName   = "/gfs.Master/ListChunkservers"
+	Master_SetReplication_FullMethodName     = "/gfs.Master/SetReplication"
+	Master_SetBalancer_FullMethodName        = "/gfs.Master/SetBalancer"
+	Master_DrainChunkserver_FullMethodName   = "/gfs.Master/DrainChunkserver"
 )
 
 // MasterClient is the client API for Master service.
@@ -41,6 +54,19 @@ type MasterClient interface {
 	ListFiles(ctx context.Context, in *ListFilesRequest, opts ...grpc.CallOption) (*ListFilesResponse, error)
 	DeleteFile(ctx context.Context, in *DeleteFileRequest, opts ...grpc.CallOption) (*DeleteFileResponse, error)
 	GetChunkLocations(ctx context.Context, in *GetChunkLocationsRequest, opts ...grpc.CallOption) (*GetChunkLocationsResponse, error)
+	UploadFileStream(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadFileStreamRequest, UploadFileResponse], error)
+	DownloadFileStream(ctx context.Context, in *DownloadFileRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DownloadFileStreamResponse], error)
+	AllocateChunk(ctx context.Context, in *AllocateChunkRequest, opts ...grpc.CallOption) (*AllocateChunkResponse, error)
+	CommitFile(ctx context.Context, in *CommitFileRequest, opts ...grpc.CallOption) (*CommitFileResponse, error)
+	ReportChunks(ctx context.Context, in *ReportChunksRequest, opts ...grpc.CallOption) (*ReportChunksResponse, error)
+	Mkdir(ctx context.Context, in *MkdirRequest, opts ...grpc.CallOption) (*MkdirResponse, error)
+	Rmdir(ctx context.Context, in *RmdirRequest, opts ...grpc.CallOption) (*RmdirResponse, error)
+	Rename(ctx context.Context, in *RenameRequest, opts ...grpc.CallOption) (*RenameResponse, error)
+	Stat(ctx context.Context, in *StatRequest, opts ...grpc.CallOption) (*StatResponse, error)
+	ListChunkservers(ctx context.Context, in *ListChunkserversRequest, opts ...grpc.CallOption) (*ListChunkserversResponse, error)
+	SetReplication(ctx context.Context, in *SetReplicationRequest, opts ...grpc.CallOption) (*SetReplicationResponse, error)
+	SetBalancer(ctx context.Context, in *SetBalancerRequest, opts ...grpc.CallOption) (*SetBalancerResponse, error)
+	DrainChunkserver(ctx context.Context, in *DrainChunkserverRequest, opts ...grpc.CallOption) (*DrainChunkserverResponse, error)
 }
 
 type masterClient struct {
@@ -111,6 +137,148 @@ func (c *masterClient) GetChunkLocations(ctx context.Context, in *GetChunkLocati
 	return out, nil
 }
 
+func (c *masterClient) UploadFileStream(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadFileStreamRequest, UploadFileResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Master_ServiceDesc.Streams[0], Master_UploadFileStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[UploadFileStreamRequest, UploadFileResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Master_UploadFileStreamClient = grpc.ClientStreamingClient[UploadFileStreamRequest, UploadFileResponse]
+
+func (c *masterClient) DownloadFileStream(ctx context.Context, in *DownloadFileRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DownloadFileStreamResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Master_ServiceDesc.Streams[1], Master_DownloadFileStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[DownloadFileRequest, DownloadFileStreamResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Master_DownloadFileStreamClient = grpc.ServerStreamingClient[DownloadFileStreamResponse]
+
+func (c *masterClient) AllocateChunk(ctx context.Context, in *AllocateChunkRequest, opts ...grpc.CallOption) (*AllocateChunkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AllocateChunkResponse)
+	err := c.cc.Invoke(ctx, Master_AllocateChunk_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) CommitFile(ctx context.Context, in *CommitFileRequest, opts ...grpc.CallOption) (*CommitFileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CommitFileResponse)
+	err := c.cc.Invoke(ctx, Master_CommitFile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) ReportChunks(ctx context.Context, in *ReportChunksRequest, opts ...grpc.CallOption) (*ReportChunksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReportChunksResponse)
+	err := c.cc.Invoke(ctx, Master_ReportChunks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) Mkdir(ctx context.Context, in *MkdirRequest, opts ...grpc.CallOption) (*MkdirResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MkdirResponse)
+	err := c.cc.Invoke(ctx, Master_Mkdir_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) Rmdir(ctx context.Context, in *RmdirRequest, opts ...grpc.CallOption) (*RmdirResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RmdirResponse)
+	err := c.cc.Invoke(ctx, Master_Rmdir_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) Rename(ctx context.Context, in *RenameRequest, opts ...grpc.CallOption) (*RenameResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RenameResponse)
+	err := c.cc.Invoke(ctx, Master_Rename_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) Stat(ctx context.Context, in *StatRequest, opts ...grpc.CallOption) (*StatResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StatResponse)
+	err := c.cc.Invoke(ctx, Master_Stat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) ListChunkservers(ctx context.Context, in *ListChunkserversRequest, opts ...grpc.CallOption) (*ListChunkserversResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListChunkserversResponse)
+	err := c.cc.Invoke(ctx, Master_ListChunkservers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) SetReplication(ctx context.Context, in *SetReplicationRequest, opts ...grpc.CallOption) (*SetReplicationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetReplicationResponse)
+	err := c.cc.Invoke(ctx, Master_SetReplication_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) SetBalancer(ctx context.Context, in *SetBalancerRequest, opts ...grpc.CallOption) (*SetBalancerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetBalancerResponse)
+	err := c.cc.Invoke(ctx, Master_SetBalancer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *masterClient) DrainChunkserver(ctx context.Context, in *DrainChunkserverRequest, opts ...grpc.CallOption) (*DrainChunkserverResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DrainChunkserverResponse)
+	err := c.cc.Invoke(ctx, Master_DrainChunkserver_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MasterServer is the server API for Master service.
 // All implementations must embed UnimplementedMasterServer
 // for forward compatibility.
@@ -123,6 +291,19 @@ type MasterServer interface {
 	ListFiles(context.Context, *ListFilesRequest) (*ListFilesResponse, error)
 	DeleteFile(context.Context, *DeleteFileRequest) (*DeleteFileResponse, error)
 	GetChunkLocations(context.Context, *GetChunkLocationsRequest) (*GetChunkLocationsResponse, error)
+	UploadFileStream(grpc.ClientStreamingServer[UploadFileStreamRequest, UploadFileResponse]) error
+	DownloadFileStream(*DownloadFileRequest, grpc.ServerStreamingServer[DownloadFileStreamResponse]) error
+	AllocateChunk(context.Context, *AllocateChunkRequest) (*AllocateChunkResponse, error)
+	CommitFile(context.Context, *CommitFileRequest) (*CommitFileResponse, error)
+	ReportChunks(context.Context, *ReportChunksRequest) (*ReportChunksResponse, error)
+	Mkdir(context.Context, *MkdirRequest) (*MkdirResponse, error)
+	Rmdir(context.Context, *RmdirRequest) (*RmdirResponse, error)
+	Rename(context.Context, *RenameRequest) (*RenameResponse, error)
+	Stat(context.Context, *StatRequest) (*StatResponse, error)
+	ListChunkservers(context.Context, *ListChunkserversRequest) (*ListChunkserversResponse, error)
+	SetReplication(context.Context, *SetReplicationRequest) (*SetReplicationResponse, error)
+	SetBalancer(context.Context, *SetBalancerRequest) (*SetBalancerResponse, error)
+	DrainChunkserver(context.Context, *DrainChunkserverRequest) (*DrainChunkserverResponse, error)
 	mustEmbedUnimplementedMasterServer()
 }
 
@@ -151,6 +332,45 @@ func (UnimplementedMasterServer) DeleteFile(context.Context, *DeleteFileRequest)
 func (UnimplementedMasterServer) GetChunkLocations(context.Context, *GetChunkLocationsRequest) (*GetChunkLocationsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetChunkLocations not implemented")
 }
+func (UnimplementedMasterServer) UploadFileStream(grpc.ClientStreamingServer[UploadFileStreamRequest, UploadFileResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method UploadFileStream not implemented")
+}
+func (UnimplementedMasterServer) DownloadFileStream(*DownloadFileRequest, grpc.ServerStreamingServer[DownloadFileStreamResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method DownloadFileStream not implemented")
+}
+func (UnimplementedMasterServer) AllocateChunk(context.Context, *AllocateChunkRequest) (*AllocateChunkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AllocateChunk not implemented")
+}
+func (UnimplementedMasterServer) CommitFile(context.Context, *CommitFileRequest) (*CommitFileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CommitFile not implemented")
+}
+func (UnimplementedMasterServer) ReportChunks(context.Context, *ReportChunksRequest) (*ReportChunksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportChunks not implemented")
+}
+func (UnimplementedMasterServer) Mkdir(context.Context, *MkdirRequest) (*MkdirResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Mkdir not implemented")
+}
+func (UnimplementedMasterServer) Rmdir(context.Context, *RmdirRequest) (*RmdirResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Rmdir not implemented")
+}
+func (UnimplementedMasterServer) Rename(context.Context, *RenameRequest) (*RenameResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Rename not implemented")
+}
+func (UnimplementedMasterServer) Stat(context.Context, *StatRequest) (*StatResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stat not implemented")
+}
+func (UnimplementedMasterServer) ListChunkservers(context.Context, *ListChunkserversRequest) (*ListChunkserversResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListChunkservers not implemented")
+}
+func (UnimplementedMasterServer) SetReplication(context.Context, *SetReplicationRequest) (*SetReplicationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetReplication not implemented")
+}
+func (UnimplementedMasterServer) SetBalancer(context.Context, *SetBalancerRequest) (*SetBalancerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetBalancer not implemented")
+}
+func (UnimplementedMasterServer) DrainChunkserver(context.Context, *DrainChunkserverRequest) (*DrainChunkserverResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DrainChunkserver not implemented")
+}
 func (UnimplementedMasterServer) mustEmbedUnimplementedMasterServer() {}
 func (UnimplementedMasterServer) testEmbeddedByValue()                {}
 
@@ -280,6 +500,222 @@ func _Master_GetChunkLocations_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Master_UploadFileStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(MasterServer).UploadFileStream(&grpc.GenericServerStream[UploadFileStreamRequest, UploadFileResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Master_UploadFileStreamServer = grpc.ClientStreamingServer[UploadFileStreamRequest, UploadFileResponse]
+
+func _Master_DownloadFileStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DownloadFileRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MasterServer).DownloadFileStream(m, &grpc.GenericServerStream[DownloadFileRequest, DownloadFileStreamResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Master_DownloadFileStreamServer = grpc.ServerStreamingServer[DownloadFileStreamResponse]
+
+func _Master_AllocateChunk_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AllocateChunkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).AllocateChunk(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_AllocateChunk_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).AllocateChunk(ctx, req.(*AllocateChunkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_CommitFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommitFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).CommitFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_CommitFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).CommitFile(ctx, req.(*CommitFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_ReportChunks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportChunksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).ReportChunks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_ReportChunks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).ReportChunks(ctx, req.(*ReportChunksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_Mkdir_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MkdirRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).Mkdir(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_Mkdir_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).Mkdir(ctx, req.(*MkdirRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_Rmdir_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RmdirRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).Rmdir(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_Rmdir_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).Rmdir(ctx, req.(*RmdirRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_Rename_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).Rename(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_Rename_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).Rename(ctx, req.(*RenameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_Stat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).Stat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_Stat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).Stat(ctx, req.(*StatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_ListChunkservers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListChunkserversRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).ListChunkservers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_ListChunkservers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).ListChunkservers(ctx, req.(*ListChunkserversRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_SetReplication_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetReplicationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).SetReplication(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_SetReplication_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).SetReplication(ctx, req.(*SetReplicationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_SetBalancer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetBalancerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).SetBalancer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_SetBalancer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).SetBalancer(ctx, req.(*SetBalancerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Master_DrainChunkserver_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DrainChunkserverRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).DrainChunkserver(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Master_DrainChunkserver_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).DrainChunkserver(ctx, req.(*DrainChunkserverRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Master_ServiceDesc is the grpc.ServiceDesc for Master service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -311,8 +747,63 @@ var Master_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetChunkLocations",
 			Handler:    _Master_GetChunkLocations_Handler,
 		},
+		{
+			MethodName: "AllocateChunk",
+			Handler:    _Master_AllocateChunk_Handler,
+		},
+		{
+			MethodName: "CommitFile",
+			Handler:    _Master_CommitFile_Handler,
+		},
+		{
+			MethodName: "ReportChunks",
+			Handler:    _Master_ReportChunks_Handler,
+		},
+		{
+			MethodName: "Mkdir",
+			Handler:    _Master_Mkdir_Handler,
+		},
+		{
+			MethodName: "Rmdir",
+			Handler:    _Master_Rmdir_Handler,
+		},
+		{
+			MethodName: "Rename",
+			Handler:    _Master_Rename_Handler,
+		},
+		{
+			MethodName: "Stat",
+			Handler:    _Master_Stat_Handler,
+		},
+		{
+			MethodName: "ListChunkservers",
+			Handler:    _Master_ListChunkservers_Handler,
+		},
+		{
+			MethodName: "SetReplication",
+			Handler:    _Master_SetReplication_Handler,
+		},
+		{
+			MethodName: "SetBalancer",
+			Handler:    _Master_SetBalancer_Handler,
+		},
+		{
+			MethodName: "DrainChunkserver",
+			Handler:    _Master_DrainChunkserver_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "UploadFileStream",
+			Handler:       _Master_UploadFileStream_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "DownloadFileStream",
+			Handler:       _Master_DownloadFileStream_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "pkg/gfs/gfs.proto",
 }
 
@@ -320,6 +811,8 @@ const (
 	Chunkserver_StoreChunk_FullMethodName    = "/gfs.Chunkserver/StoreChunk"
 	Chunkserver_RetrieveChunk_FullMethodName = "/gfs.Chunkserver/RetrieveChunk"
 	Chunkserver_DeleteChunk_FullMethodName   = "/gfs.Chunkserver/DeleteChunk"
+	Chunkserver_WriteChunk_FullMethodName    = "/gfs.Chunkserver/WriteChunk"
+	Chunkserver_ReadChunk_FullMethodName     = "/gfs.Chunkserver/ReadChunk"
 )
 
 // ChunkserverClient is the client API for Chunkserver service.
@@ -329,6 +822,8 @@ type ChunkserverClient interface {
 	StoreChunk(ctx context.Context, in *StoreChunkRequest, opts ...grpc.CallOption) (*StoreChunkResponse, error)
 	RetrieveChunk(ctx context.Context, in *RetrieveChunkRequest, opts ...grpc.CallOption) (*RetrieveChunkResponse, error)
 	DeleteChunk(ctx context.Context, in *DeleteChunkRequest, opts ...grpc.CallOption) (*DeleteChunkResponse, error)
+	WriteChunk(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[WriteChunkRequest, StoreChunkResponse], error)
+	ReadChunk(ctx context.Context, in *ReadChunkRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ReadChunkResponse], error)
 }
 
 type chunkserverClient struct {
@@ -369,6 +864,38 @@ func (c *chunkserverClient) DeleteChunk(ctx context.Context, in *DeleteChunkRequ
 	return out, nil
 }
 
+func (c *chunkserverClient) WriteChunk(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[WriteChunkRequest, StoreChunkResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Chunkserver_ServiceDesc.Streams[0], Chunkserver_WriteChunk_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WriteChunkRequest, StoreChunkResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Chunkserver_WriteChunkClient = grpc.ClientStreamingClient[WriteChunkRequest, StoreChunkResponse]
+
+func (c *chunkserverClient) ReadChunk(ctx context.Context, in *ReadChunkRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ReadChunkResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Chunkserver_ServiceDesc.Streams[1], Chunkserver_ReadChunk_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ReadChunkRequest, ReadChunkResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Chunkserver_ReadChunkClient = grpc.ServerStreamingClient[ReadChunkResponse]
+
 // ChunkserverServer is the server API for Chunkserver service.
 // All implementations must embed UnimplementedChunkserverServer
 // for forward compatibility.
@@ -376,6 +903,8 @@ type ChunkserverServer interface {
 	StoreChunk(context.Context, *StoreChunkRequest) (*StoreChunkResponse, error)
 	RetrieveChunk(context.Context, *RetrieveChunkRequest) (*RetrieveChunkResponse, error)
 	DeleteChunk(context.Context, *DeleteChunkRequest) (*DeleteChunkResponse, error)
+	WriteChunk(grpc.ClientStreamingServer[WriteChunkRequest, StoreChunkResponse]) error
+	ReadChunk(*ReadChunkRequest, grpc.ServerStreamingServer[ReadChunkResponse]) error
 	mustEmbedUnimplementedChunkserverServer()
 }
 
@@ -395,6 +924,12 @@ func (UnimplementedChunkserverServer) RetrieveChunk(context.Context, *RetrieveCh
 func (UnimplementedChunkserverServer) DeleteChunk(context.Context, *DeleteChunkRequest) (*DeleteChunkResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteChunk not implemented")
 }
+func (UnimplementedChunkserverServer) WriteChunk(grpc.ClientStreamingServer[WriteChunkRequest, StoreChunkResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method WriteChunk not implemented")
+}
+func (UnimplementedChunkserverServer) ReadChunk(*ReadChunkRequest, grpc.ServerStreamingServer[ReadChunkResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ReadChunk not implemented")
+}
 func (UnimplementedChunkserverServer) mustEmbedUnimplementedChunkserverServer() {}
 func (UnimplementedChunkserverServer) testEmbeddedByValue()                     {}
 
@@ -470,6 +1005,24 @@ func _Chunkserver_DeleteChunk_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Chunkserver_WriteChunk_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ChunkserverServer).WriteChunk(&grpc.GenericServerStream[WriteChunkRequest, StoreChunkResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Chunkserver_WriteChunkServer = grpc.ClientStreamingServer[WriteChunkRequest, StoreChunkResponse]
+
+func _Chunkserver_ReadChunk_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ReadChunkRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ChunkserverServer).ReadChunk(m, &grpc.GenericServerStream[ReadChunkRequest, ReadChunkResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Chunkserver_ReadChunkServer = grpc.ServerStreamingServer[ReadChunkResponse]
+
 // Chunkserver_ServiceDesc is the grpc.ServiceDesc for Chunkserver service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -490,6 +1043,17 @@ var Chunkserver_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _Chunkserver_DeleteChunk_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WriteChunk",
+			Handler:       _Chunkserver_WriteChunk_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ReadChunk",
+			Handler:       _Chunkserver_ReadChunk_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "pkg/gfs/gfs.proto",
 }
@@ -0,0 +1,27 @@
+package gfs
+
+import "fmt"
+
+// chunkHandleLen is the length of a chunk handle in its canonical form
+const chunkHandleLen = 16
+
+// FormatChunkHandle renders a 64-bit chunk handle in its canonical form:
+// 16 lowercase hexadecimal digits.
+func FormatChunkHandle(handle uint64) string {
+	return fmt.Sprintf("%016x", handle)
+}
+
+// ValidChunkHandle reports whether s is a chunk handle in canonical form.
+// Chunkservers use handles as file names, so anything else is rejected.
+func ValidChunkHandle(s string) bool {
+	if len(s) != chunkHandleLen {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,244 @@
+package gfs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+)
+
+// DialChunkserver returns a client for the chunkserver holding a replica. Replicas
+// are named by whatever the caller uses to find chunkservers: an address or an ID.
+type DialChunkserver func(replica string) (ChunkserverClient, error)
+
+// ChunkStreamReader reads the data of a ReadChunk stream. It checks the data
+// against the chunk size announced in the first frame, so a stream that ends
+// early fails with io.ErrUnexpectedEOF instead of looking like a short chunk.
+type ChunkStreamReader struct {
+	stream Chunkserver_ReadChunkClient
+	offset int64  // chunk offset just past the data received so far
+	size   int64  // announced chunk size; -1 until known
+	data   []byte // rest of the current frame
+}
+
+// NewChunkStreamReader reads a stream opened at offset in the chunk
+func NewChunkStreamReader(stream Chunkserver_ReadChunkClient, offset int64) *ChunkStreamReader {
+	return &ChunkStreamReader{stream: stream, offset: offset, size: -1}
+}
+
+// next returns the data of the next frame, or io.EOF once the whole chunk was received
+func (r *ChunkStreamReader) next() ([]byte, error) {
+	frame, err := r.stream.Recv()
+	if err == io.EOF {
+		// Chunkservers that do not announce sizes cannot be checked
+		if r.size >= 0 && r.offset != r.size {
+			return nil, fmt.Errorf("%w: stream ended at byte %d of %d", io.ErrUnexpectedEOF, r.offset, r.size)
+		}
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if frame.ChunkSize != nil {
+		if r.size >= 0 && frame.GetChunkSize() != r.size {
+			return nil, fmt.Errorf("replica holds %d bytes, not %d", frame.GetChunkSize(), r.size)
+		}
+		r.size = frame.GetChunkSize()
+	}
+	data := frame.GetData()
+	if r.size >= 0 && r.offset+int64(len(data)) > r.size {
+		return nil, fmt.Errorf("stream runs past the announced size of %d bytes", r.size)
+	}
+	r.offset += int64(len(data))
+	return data, nil
+}
+
+// Read implements io.Reader
+func (r *ChunkStreamReader) Read(p []byte) (int, error) {
+	for len(r.data) == 0 {
+		data, err := r.next()
+		if err != nil {
+			return 0, err
+		}
+		r.data = data
+	}
+
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+// ReadChunkReplicas streams a chunk at version into w and returns the bytes
+// written. It fails over between replicas, resuming at the offset where the
+// previous one stopped; replicas that disagree about the chunk's size are skipped.
+func ReadChunkReplicas(ctx context.Context, chunkHandle string, version uint64, replicas []string, dial DialChunkserver, w io.Writer) (int64, error) {
+	dst := &destWriter{w: w}
+	size := int64(-1)
+
+	for _, replica := range replicas {
+		chunkserverClient, err := dial(replica)
+		if err != nil {
+			log.Printf("Failed to connect to chunkserver %s: %v", replica, err)
+			continue
+		}
+
+		size, err = readChunkFrom(ctx, chunkserverClient, chunkHandle, version, dst.n, size, dst)
+		if err == nil {
+			return dst.n, nil
+		}
+		// A failed write means the caller went away; no replica can fix that
+		if dst.err != nil {
+			return dst.n, dst.err
+		}
+		log.Printf("Failed to read chunk %s from %s: %v", chunkHandle, replica, err)
+	}
+
+	return dst.n, fmt.Errorf("no replica of chunk %s could be read", chunkHandle)
+}
+
+// destWriter counts the bytes written to w and remembers why writing failed
+type destWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+// Write implements io.Writer
+func (d *destWriter) Write(p []byte) (int, error) {
+	n, err := d.w.Write(p)
+	d.n += int64(n)
+	d.err = err
+	return n, err
+}
+
+// readChunkFrom streams a chunk of the given size (-1 if unknown) from one
+// replica into w, starting at offset, and returns the size the replica announced
+func readChunkFrom(ctx context.Context, chunkserverClient ChunkserverClient, chunkHandle string, version uint64, offset, size int64, w io.Writer) (int64, error) {
+	// Abandoning a replica midway must not leave its stream open
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Replicas holding any other version are stale and refuse the read
+	stream, err := chunkserverClient.ReadChunk(ctx, &ReadChunkRequest{
+		ChunkHandle: chunkHandle,
+		Offset:      offset,
+		Version:     version,
+	})
+	if err != nil {
+		return size, err
+	}
+
+	reader := NewChunkStreamReader(stream, offset)
+	reader.size = size
+	for {
+		data, err := reader.next()
+		if err == io.EOF {
+			return reader.size, nil
+		}
+		if err != nil {
+			return reader.size, err
+		}
+		if _, err := w.Write(data); err != nil {
+			return reader.size, err
+		}
+	}
+}
+
+// ChunkWriter streams one chunk to all of its replicas at once, dropping
+// replicas that fail
+type ChunkWriter struct {
+	chunkHandle string
+	replicas    []replicaStream
+	size        int64
+}
+
+// replicaStream is an open chunk write stream to one replica
+type replicaStream struct {
+	replica string
+	stream  Chunkserver_WriteChunkClient
+}
+
+// OpenChunkWriter opens a write stream for a chunk at version on every replica it can reach
+func OpenChunkWriter(ctx context.Context, chunkHandle string, version uint64, replicas []string, dial DialChunkserver) *ChunkWriter {
+	w := &ChunkWriter{chunkHandle: chunkHandle}
+
+	for _, replica := range replicas {
+		chunkserverClient, err := dial(replica)
+		if err != nil {
+			log.Printf("Failed to connect to chunkserver %s: %v", replica, err)
+			continue
+		}
+
+		stream, err := chunkserverClient.WriteChunk(ctx)
+		if err == nil {
+			// The first frame names the chunk and its version, so even an empty chunk gets created
+			err = stream.Send(&WriteChunkRequest{ChunkHandle: chunkHandle, Version: version})
+		}
+		if err != nil {
+			log.Printf("Failed to open chunk %s on %s: %v", chunkHandle, replica, err)
+			continue
+		}
+		w.replicas = append(w.replicas, replicaStream{replica: replica, stream: stream})
+	}
+
+	return w
+}
+
+// Write implements io.Writer. It sends p to every replica in bounded frames and
+// fails once no replica is left.
+func (w *ChunkWriter) Write(p []byte) (int, error) {
+	var written int
+	for {
+		if len(w.replicas) == 0 {
+			return written, fmt.Errorf("no replica of chunk %s accepted the data", w.chunkHandle)
+		}
+		if len(p) == 0 {
+			return written, nil
+		}
+
+		n := min(len(p), StreamFrameSize)
+		frame := &WriteChunkRequest{Data: p[:n]}
+
+		healthy := w.replicas[:0]
+		for _, replica := range w.replicas {
+			if err := replica.stream.Send(frame); err != nil {
+				log.Printf("Failed to write chunk %s to %s: %v", w.chunkHandle, replica.replica, err)
+				continue
+			}
+			healthy = append(healthy, replica)
+		}
+		w.replicas = healthy
+
+		if len(w.replicas) > 0 {
+			w.size += int64(n)
+			written += n
+			p = p[n:]
+		}
+	}
+}
+
+// Size returns the number of bytes written so far
+func (w *ChunkWriter) Size() int64 {
+	return w.size
+}
+
+// Close finishes every write stream and returns the replicas that stored the chunk
+func (w *ChunkWriter) Close() []string {
+	var stored []string
+
+	for _, replica := range w.replicas {
+		resp, err := replica.stream.CloseAndRecv()
+		if err != nil {
+			log.Printf("Failed to store chunk %s on %s: %v", w.chunkHandle, replica.replica, err)
+			continue
+		}
+		if !resp.GetSuccess() {
+			log.Printf("Chunkserver %s returned error for chunk %s: %s", replica.replica, w.chunkHandle, resp.GetMessage())
+			continue
+		}
+		stored = append(stored, replica.replica)
+	}
+
+	return stored
+}
@@ -0,0 +1,5 @@
+package gfs
+
+// StreamFrameSize is the maximum payload carried by one frame of a streaming RPC.
+// It keeps every message well below gRPC's default 4 MiB limit.
+const StreamFrameSize = 1 << 20
@@ -0,0 +1,22 @@
+package gfs
+
+import "strings"
+
+// FormatTopology renders the known failure domains of a chunkserver, e.g.
+// "zone=us-east-1a rack=r12 host=node7", or "unknown" if none were reported
+func FormatTopology(t *Topology) string {
+	var labels []string
+	for _, label := range []struct{ name, value string }{
+		{"zone", t.GetZone()},
+		{"rack", t.GetRack()},
+		{"host", t.GetHost()},
+	} {
+		if label.value != "" {
+			labels = append(labels, label.name+"="+label.value)
+		}
+	}
+	if len(labels) == 0 {
+		return "unknown"
+	}
+	return strings.Join(labels, " ")
+}